@@ -32,6 +32,19 @@ func (b *body) NewField(commit *gc.Commit) (huh.Field, error) {
 		Editor("vim"), nil
 }
 
+// Inputs lets the body be given with --body when running without a form.
+func (b *body) Inputs() []gc.Input {
+	return []gc.Input{{
+		Key:   "body",
+		Short: "b",
+		Usage: "Detailed description of the changes",
+		Set: func(commit *gc.Commit, values []string) error {
+			commit.Body = values[0]
+			return nil
+		},
+	}}
+}
+
 func (b *body) PostProcess(commit *gc.Commit) error {
 	if commit.Body == "" {
 		return nil
@@ -49,8 +62,15 @@ func (b *body) PostProcess(commit *gc.Commit) error {
 	return nil
 }
 
+// Reset empties the body when the field is no longer shown.
+func (b *body) Reset(commit *gc.Commit, shown bool) {
+	if !shown {
+		commit.Body = ""
+	}
+}
+
 func (b *body) Name() string {
-	return MODULE_NAME
+	return b.config.Name
 }
 
 func (b *body) Config() gc.ModuleConfig {
@@ -75,3 +95,7 @@ func (b *body) IsActive() bool {
 func New() gc.Module {
 	return &body{config: gc.ModuleConfig{Name: MODULE_NAME}}
 }
+
+func init() {
+	gc.Register(MODULE_NAME, New)
+}
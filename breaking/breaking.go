@@ -3,6 +3,9 @@
 package breaking
 
 import (
+	"fmt"
+	"strconv"
+
 	"github.com/charmbracelet/huh"
 	gc "github.com/nantli/goodcommit"
 )
@@ -19,10 +22,10 @@ func (b *breaking) LoadConfig() error {
 }
 
 // NewField returns a new huh.Confirm field for indicating breaking changes.
-// Only appears if the commit type is "feat" or "fix".
+// Unless the module has a when expression, it only appears if the commit type is "feat" or "fix".
 func (b *breaking) NewField(commit *gc.Commit) (huh.Field, error) {
 
-	if commit.Type != "feat" && commit.Type != "fix" {
+	if b.config.When == "" && commit.Type != "feat" && commit.Type != "fix" {
 		return nil, nil
 	}
 
@@ -33,12 +36,56 @@ func (b *breaking) NewField(commit *gc.Commit) (huh.Field, error) {
 		Value(&commit.Breaking), nil
 }
 
+// Inputs lets a breaking change be flagged with --breaking when running without a form.
+// As in the form, only "feat" and "fix" commits can be breaking unless there is a when expression.
+func (b *breaking) Inputs() []gc.Input {
+	return []gc.Input{{
+		Key:   "breaking",
+		Kind:  gc.InputBool,
+		Usage: "Mark the commit as introducing a breaking change",
+		Set: func(commit *gc.Commit, values []string) error {
+			isBreaking, err := strconv.ParseBool(values[0])
+			if err != nil {
+				return err
+			}
+			if isBreaking && b.config.When == "" && commit.Type != "feat" && commit.Type != "fix" {
+				return fmt.Errorf("only feat and fix commits can introduce breaking changes")
+			}
+			commit.Breaking = isBreaking
+			return nil
+		},
+	}}
+}
+
+// Validate checks that only the commits that can introduce breaking changes are marked as
+// breaking: those the when expression holds for, or else "feat" and "fix" commits.
+func (b *breaking) Validate(commit *gc.Commit) []gc.Violation {
+	if !commit.Breaking {
+		return nil
+	}
+	if b.config.When != "" && !b.config.Shown(commit) {
+		return []gc.Violation{{Field: "breaking", Message: fmt.Sprintf("breaking changes are only allowed when %s", b.config.When)}}
+	}
+	if b.config.When == "" && commit.Type != "feat" && commit.Type != "fix" {
+		return []gc.Violation{{Field: "breaking", Message: "only feat and fix commits can introduce breaking changes"}}
+	}
+	return nil
+}
+
 // PostProcess adds ! symbol to commit type if the commit is a breaking change
 func (b *breaking) PostProcess(commit *gc.Commit) error {
 
 	return nil
 }
 
+// Reset unmarks the commit as breaking when the field is no longer shown, e.g. when the type
+// changes from feat to docs.
+func (b *breaking) Reset(commit *gc.Commit, shown bool) {
+	if !shown {
+		commit.Breaking = false
+	}
+}
+
 func (b *breaking) Config() gc.ModuleConfig {
 	return b.config
 }
@@ -48,7 +95,7 @@ func (b *breaking) SetConfig(config gc.ModuleConfig) {
 }
 
 func (b *breaking) Name() string {
-	return MODULE_NAME
+	return b.config.Name
 }
 
 func (b *breaking) InitCommitInfo(commit *gc.Commit) error {
@@ -65,3 +112,7 @@ func (b *breaking) IsActive() bool {
 func New() gc.Module {
 	return &breaking{config: gc.ModuleConfig{Name: MODULE_NAME}}
 }
+
+func init() {
+	gc.Register(MODULE_NAME, New)
+}
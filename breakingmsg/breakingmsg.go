@@ -6,6 +6,7 @@ package breakingmsg
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/charmbracelet/huh"
 	gc "github.com/nantli/goodcommit"
@@ -26,10 +27,10 @@ func (bm *breakingMsg) LoadConfig() error {
 }
 
 // NewField returns a huh.Text field for the breaking message.
-// It only appears if the commit is marked as breaking.
+// Unless the module has a when expression, it only appears if the commit is marked as breaking.
 func (bm *breakingMsg) NewField(commit *gc.Commit) (huh.Field, error) {
 	// Only show this field if the commit is marked as breaking and not a chore
-	if commit.Breaking {
+	if commit.Breaking || bm.config.When != "" {
 		return huh.NewText().
 			Title("💥・Breaking Changes Details").
 			Description("Provide detailed information about the breaking changes.\n").
@@ -39,8 +40,26 @@ func (bm *breakingMsg) NewField(commit *gc.Commit) (huh.Field, error) {
 	return nil, nil
 }
 
+// Inputs lets the breaking changes details be given with --breaking-message when running without a form.
+func (bm *breakingMsg) Inputs() []gc.Input {
+	return []gc.Input{{
+		Key:   "breaking-message",
+		Usage: "Details about the breaking changes",
+		Set: func(commit *gc.Commit, values []string) error {
+			if !commit.Breaking && bm.config.When == "" {
+				return fmt.Errorf("commit is not marked as breaking")
+			}
+			msg := values[0]
+			commit.Extras["breakingmsg"] = &msg
+			return nil
+		},
+	}}
+}
+
+// PostProcess formats the breaking message as a sentence and adds it as the first trailer. It does
+// nothing unless the commit is marked as breaking, so an earlier message is not kept.
 func (bm *breakingMsg) PostProcess(commit *gc.Commit) error {
-	if commit.Extras["breakingmsg"] == nil || *commit.Extras["breakingmsg"] == "" {
+	if !commit.Breaking || commit.Extras["breakingmsg"] == nil || *commit.Extras["breakingmsg"] == "" {
 		return nil
 	}
 	// Capitalize first letter of breaking message
@@ -50,11 +69,20 @@ func (bm *breakingMsg) PostProcess(commit *gc.Commit) error {
 	if (*commit.Extras["breakingmsg"])[len(*commit.Extras["breakingmsg"])-1] != '.' {
 		*commit.Extras["breakingmsg"] += "."
 	}
-	// At the end of the body, add a new line and the breaking message
-	commit.Body = fmt.Sprintf("%s\n\nBREAKING CHANGE: %s", commit.Body, *commit.Extras["breakingmsg"])
+	// The breaking change goes first, before the trailers of the other modules
+	commit.RemoveTrailers("BREAKING CHANGE", "BREAKING-CHANGE")
+	commit.Trailers = slices.Insert(commit.Trailers, 0, gc.Trailer{Key: "BREAKING CHANGE", Value: *commit.Extras["breakingmsg"]})
 	return nil
 }
 
+// Reset empties the breaking message when the field is no longer shown, e.g. when the commit is
+// no longer marked as breaking.
+func (bm *breakingMsg) Reset(commit *gc.Commit, shown bool) {
+	if !shown && commit.Extras["breakingmsg"] != nil {
+		*commit.Extras["breakingmsg"] = ""
+	}
+}
+
 func (bm *breakingMsg) Config() gc.ModuleConfig {
 	return bm.config
 }
@@ -64,10 +92,17 @@ func (bm *breakingMsg) SetConfig(config gc.ModuleConfig) {
 }
 
 func (bm *breakingMsg) Name() string {
-	return MODULE_NAME
+	return bm.config.Name
 }
 
+// InitCommitInfo initializes the commit with a placeholder for the breaking message in the extras
+// map, unless the commit already has a breaking message (e.g. when amending). Its trailer is
+// added again in PostProcess.
 func (bm *breakingMsg) InitCommitInfo(commit *gc.Commit) error {
+	commit.RemoveTrailers("BREAKING CHANGE", "BREAKING-CHANGE")
+	if commit.Extras["breakingmsg"] != nil {
+		return nil
+	}
 	placeholder := ""
 	commit.Extras["breakingmsg"] = &placeholder
 	return nil
@@ -82,3 +117,7 @@ func (bm *breakingMsg) IsActive() bool {
 func New() gc.Module {
 	return &breakingMsg{config: gc.ModuleConfig{Name: MODULE_NAME}}
 }
+
+func init() {
+	gc.Register(MODULE_NAME, New)
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	gc "github.com/nantli/goodcommit"
+)
+
+// runConfig handles the "goodcommit config" commands.
+func runConfig(args []string) int {
+	if len(args) == 0 {
+		fmt.Println("Usage: goodcommit config show|validate|schema")
+		return 1
+	}
+
+	switch args[0] {
+	case "show":
+		return configShow(args[1:])
+	case "validate":
+		return configValidate(args[1:])
+	case "schema":
+		return configSchema(args[1:])
+	default:
+		fmt.Printf("Error: unknown config command %q\n", args[0])
+		return 1
+	}
+}
+
+// configShow prints the effective configuration, and with --origin the file each value came from.
+func configShow(args []string) int {
+	fs := flag.NewFlagSet("config show", flag.ExitOnError)
+	configPath := os.Getenv("GOODCOMMIT_CONFIG_PATH")
+	fs.StringVar(&configPath, "config", configPath, "Path to a configuration file")
+	origin := fs.Bool("origin", false, "Show the file each value came from")
+	fs.Parse(args)
+
+	layers := gc.DiscoverConfig(configPath)
+	cfg, err := gc.LoadConfig(layers)
+	if err != nil {
+		fmt.Println("Error occurred while loading configuration:", err)
+		return 1
+	}
+
+	if !*origin {
+		out, _ := json.MarshalIndent(cfg, "", "    ")
+		fmt.Println(string(out))
+		return 0
+	}
+
+	fmt.Println("# Configuration files, from lowest to highest precedence:")
+	for _, layer := range layers {
+		fmt.Printf("#   %-6s %s\n", layer.Name, layer.Path)
+	}
+	for _, mc := range cfg.ModulesToActivate {
+		fmt.Printf("\n%s:\n", mc.Name)
+		v := reflect.ValueOf(mc)
+		for i := 0; i < v.NumField(); i++ {
+			key, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("json"), ",")
+			value, _ := json.Marshal(v.Field(i).Interface())
+			source := "default"
+			if layer, ok := cfg.Origins[mc.Name][key]; ok {
+				source = layer.Name + ": " + layer.Path
+			}
+			fmt.Printf("  %-14s %-40s # %s\n", key, value, source)
+		}
+	}
+	return 0
+}
+
+// configValidate checks the effective configuration and the files of the active modules,
+// printing every problem found.
+func configValidate(args []string) int {
+	fs := flag.NewFlagSet("config validate", flag.ExitOnError)
+	configPath := os.Getenv("GOODCOMMIT_CONFIG_PATH")
+	fs.StringVar(&configPath, "config", configPath, "Path to a configuration file")
+	fs.Parse(args)
+
+	cfg, err := gc.LoadConfig(gc.DiscoverConfig(configPath))
+	if err == nil {
+		err = gc.ValidateConfig(cfg)
+	}
+	if err != nil {
+		fmt.Println("Configuration is not valid:")
+		for _, line := range strings.Split(err.Error(), "\n") {
+			fmt.Println("  -", line)
+		}
+		return 1
+	}
+	fmt.Println("Configuration is valid")
+	return 0
+}
+
+// configSchema prints the JSON Schema of the configuration file, or of the file of the given
+// module. With --out, the schemas of the configuration and of every module are written to a directory.
+func configSchema(args []string) int {
+	fs := flag.NewFlagSet("config schema", flag.ExitOnError)
+	out := fs.String("out", "", "Write all the schemas to this directory")
+	fs.Parse(args)
+
+	schemas := map[string]gc.Schema{"config": gc.ConfigSchema()}
+	for _, m := range registeredModules() {
+		if s, ok := m.(gc.SchemaModule); ok {
+			schemas[m.Name()] = s.Schema()
+		}
+	}
+
+	if *out != "" {
+		if err := os.MkdirAll(*out, 0o755); err != nil {
+			fmt.Println("Error creating schemas directory:", err)
+			return 1
+		}
+		for name, schema := range schemas {
+			content, _ := json.MarshalIndent(schema, "", "    ")
+			path := filepath.Join(*out, name+".schema.json")
+			if err := os.WriteFile(path, append(content, '\n'), 0o644); err != nil {
+				fmt.Println("Error writing schema:", err)
+				return 1
+			}
+			fmt.Println("Wrote", path)
+		}
+		return 0
+	}
+
+	name := "config"
+	if fs.NArg() > 0 {
+		name = fs.Arg(0)
+	}
+	schema, ok := schemas[name]
+	if !ok {
+		fmt.Printf("Error: module %q has no configuration file schema\n", name)
+		return 1
+	}
+	content, _ := json.MarshalIndent(schema, "", "    ")
+	fmt.Println(string(content))
+	return 0
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/charmbracelet/huh"
+	gc "github.com/nantli/goodcommit"
+)
+
+// runDrafts handles the "goodcommit drafts" commands.
+func runDrafts(args []string) int {
+	if len(args) == 0 {
+		fmt.Println("Usage: goodcommit drafts list|show|drop")
+		return 1
+	}
+
+	// Drafts of deleted branches are no longer needed
+	_, _ = gc.Prune()
+
+	switch args[0] {
+	case "list":
+		return draftsList()
+	case "show":
+		return draftsShow(args[1:])
+	case "drop":
+		return draftsDrop(args[1:])
+	default:
+		fmt.Printf("Error: unknown drafts command %q\n", args[0])
+		return 1
+	}
+}
+
+// draftsList prints the saved drafts, the most recent first.
+func draftsList() int {
+	drafts, err := gc.Drafts()
+	if err != nil {
+		fmt.Println("Error reading drafts:", err)
+		return 1
+	}
+	if len(drafts) == 0 {
+		fmt.Println("No drafts.")
+		return 0
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tBRANCH\tUPDATED\tCOMMIT")
+	for _, d := range drafts {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", d.ID, d.Branch, d.Updated.Format("2006-01-02 15:04"), d.Summary())
+	}
+	w.Flush()
+	return 0
+}
+
+// draftsShow prints the values of a draft.
+func draftsShow(args []string) int {
+	if len(args) != 1 {
+		fmt.Println("Usage: goodcommit drafts show <id>")
+		return 1
+	}
+	d, err := gc.LoadDraft(args[0])
+	if err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	out, _ := json.MarshalIndent(d, "", "    ")
+	fmt.Println(string(out))
+	return 0
+}
+
+// draftsDrop removes the given drafts, or all of them with --all.
+func draftsDrop(args []string) int {
+	fs := flag.NewFlagSet("drafts drop", flag.ExitOnError)
+	all := fs.Bool("all", false, "Drop all the drafts")
+	fs.Parse(args)
+	if !*all && fs.NArg() == 0 {
+		fmt.Println("Usage: goodcommit drafts drop [--all] <id>...")
+		return 1
+	}
+
+	var drafts []*gc.Draft
+	if *all {
+		var err error
+		if drafts, err = gc.Drafts(); err != nil {
+			fmt.Println("Error reading drafts:", err)
+			return 1
+		}
+	}
+	for _, id := range fs.Args() {
+		d, err := gc.LoadDraft(id)
+		if err != nil {
+			fmt.Println("Error:", err)
+			return 1
+		}
+		drafts = append(drafts, d)
+	}
+	for _, d := range drafts {
+		if err := d.Drop(); err != nil {
+			fmt.Printf("Error dropping draft %s: %s\n", d.ID, err)
+			return 1
+		}
+		fmt.Println("Dropped draft", d.ID)
+	}
+	return 0
+}
+
+// chooseDraft returns the draft the form is saved to: the last draft of the current branch if
+// the user chooses to resume it, otherwise a new one. Outside a git repository there is no draft.
+func chooseDraft(accessible bool) (draft *gc.Draft, resumed bool) {
+	drafts, err := gc.Drafts()
+	if err != nil {
+		return nil, false
+	}
+	draft = gc.NewDraft()
+	var last *gc.Draft
+	for _, d := range drafts {
+		if d.Branch == draft.Branch {
+			last = d
+			break
+		}
+	}
+	if last == nil {
+		return draft, false
+	}
+
+	var choice string
+	err = huh.NewSelect[string]().
+		Title("📝・Resume the draft from "+last.Updated.Format("Jan 2 15:04")+"?").
+		Description(last.Summary()).
+		Options(
+			huh.NewOption("Resume it", "resume"),
+			huh.NewOption("Start over, keeping it", "keep"),
+			huh.NewOption("Start over, dropping it", "drop"),
+		).
+		Value(&choice).
+		WithTheme(huh.ThemeCharm()).
+		WithAccessible(accessible).
+		Run()
+	if err != nil {
+		return draft, false
+	}
+	switch choice {
+	case "resume":
+		return last, true
+	case "drop":
+		if err := last.Drop(); err != nil {
+			fmt.Println("Error dropping draft:", err)
+		}
+	}
+	return draft, false
+}
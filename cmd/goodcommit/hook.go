@@ -0,0 +1,294 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	gc "github.com/nantli/goodcommit"
+	"github.com/nantli/goodcommit/goodcommiter"
+)
+
+// hookMarker identifies the hook scripts written by goodcommit.
+const hookMarker = "# Installed by goodcommit"
+
+// chainedSuffix is appended to the name of an existing hook that goodcommit keeps chained.
+const chainedSuffix = ".pre-goodcommit"
+
+// hookNames are the git hooks that goodcommit can be installed as.
+var hookNames = []string{"prepare-commit-msg", "commit-msg"}
+
+// defaultHookSources are the prepare-commit-msg sources for which the form is shown.
+// An empty source means a plain "git commit", "template" a commit using commit.template.
+var defaultHookSources = []string{"", "template"}
+
+// runHook handles the "goodcommit hook install|uninstall|run" commands.
+func runHook(args []string) int {
+	if len(args) == 0 {
+		fmt.Println("Usage: goodcommit hook install|uninstall|run [flags]")
+		return 1
+	}
+
+	var err error
+	switch args[0] {
+	case "install":
+		err = hookInstall(args[1:])
+	case "uninstall":
+		err = hookUninstall(args[1:])
+	case "run":
+		return hookRun(args[1:])
+	default:
+		err = fmt.Errorf("unknown hook command %q", args[0])
+	}
+	if err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	return 0
+}
+
+// hookInstall writes the hook script into the hooks directory, keeping any existing hook chained.
+func hookInstall(args []string) error {
+	fs := flag.NewFlagSet("hook install", flag.ExitOnError)
+	hook := fs.String("type", "prepare-commit-msg", "Hook to install, prepare-commit-msg or commit-msg")
+	configPath := fs.String("config", "", "Path to a configuration file used by the hook")
+	on := fs.String("on", "", "Extra prepare-commit-msg sources to run on, comma separated (message, merge, squash, commit)")
+	allowExec := fs.Bool("allow-exec", false, "Run the external modules set in the repository configuration files")
+	fs.Parse(args)
+
+	if !slices.Contains(hookNames, *hook) {
+		return fmt.Errorf("unknown hook %q, use one of: %s", *hook, strings.Join(hookNames, ", "))
+	}
+
+	dir, err := hooksDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	path := filepath.Join(dir, *hook)
+
+	// Keep the existing hook chained, unless it was written by goodcommit
+	if raw, err := os.ReadFile(path); err == nil && !bytes.Contains(raw, []byte(hookMarker)) {
+		if _, err := os.Stat(path + chainedSuffix); err == nil {
+			return fmt.Errorf("%s already exists, remove it before installing the hook", path+chainedSuffix)
+		}
+		if err := os.Rename(path, path+chainedSuffix); err != nil {
+			return fmt.Errorf("error keeping existing hook: %w", err)
+		}
+		fmt.Printf("Existing %s hook kept as %s and chained.\n", *hook, path+chainedSuffix)
+	}
+
+	executable := "goodcommit"
+	if _, err := exec.LookPath(executable); err != nil {
+		if executable, err = os.Executable(); err != nil {
+			return err
+		}
+	}
+	var runArgs []string
+	if *configPath != "" {
+		abs, err := filepath.Abs(*configPath)
+		if err != nil {
+			return err
+		}
+		runArgs = append(runArgs, "--config", strconv.Quote(abs))
+	}
+	if *on != "" {
+		runArgs = append(runArgs, "--on", strconv.Quote(*on))
+	}
+	if *allowExec {
+		runArgs = append(runArgs, "--allow-exec")
+	}
+	runArgs = append(runArgs, *hook)
+
+	script := fmt.Sprintf(`#!/bin/sh
+%s, run "goodcommit hook uninstall --type %s" to remove it.
+chained="$0%s"
+if [ -x "$chained" ]; then
+	"$chained" "$@" || exit $?
+fi
+exec %s hook run %s "$@"
+`, hookMarker, *hook, chainedSuffix, strconv.Quote(executable), strings.Join(runArgs, " "))
+
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		return fmt.Errorf("error writing hook: %w", err)
+	}
+	fmt.Printf("Installed %s hook in %s\n", *hook, path)
+	return nil
+}
+
+// hookUninstall removes the hook script written by goodcommit and restores the chained hook.
+func hookUninstall(args []string) error {
+	fs := flag.NewFlagSet("hook uninstall", flag.ExitOnError)
+	hook := fs.String("type", "prepare-commit-msg", "Hook to uninstall, prepare-commit-msg or commit-msg")
+	fs.Parse(args)
+
+	dir, err := hooksDir()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, *hook)
+
+	raw, err := os.ReadFile(path)
+	if err != nil || !bytes.Contains(raw, []byte(hookMarker)) {
+		return fmt.Errorf("no goodcommit %s hook installed in %s", *hook, dir)
+	}
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	if _, err := os.Stat(path + chainedSuffix); err == nil {
+		if err := os.Rename(path+chainedSuffix, path); err != nil {
+			return fmt.Errorf("error restoring chained hook: %w", err)
+		}
+		fmt.Printf("Restored the previous %s hook.\n", *hook)
+	}
+	fmt.Printf("Uninstalled %s hook from %s\n", *hook, path)
+	return nil
+}
+
+// hookRun is called by the hook scripts with the arguments git passes to the hook.
+// As prepare-commit-msg it runs the form against the terminal and writes the message
+// into the file given by git, as commit-msg it lints that file.
+func hookRun(args []string) int {
+	fs := flag.NewFlagSet("hook run", flag.ExitOnError)
+	configPath := os.Getenv("GOODCOMMIT_CONFIG_PATH")
+	fs.StringVar(&configPath, "config", configPath, "Path to a configuration file")
+	on := fs.String("on", "", "Extra prepare-commit-msg sources to run on, comma separated (message, merge, squash, commit)")
+	gc.AllowRepoExec, _ = strconv.ParseBool(os.Getenv("GOODCOMMIT_ALLOW_EXEC"))
+	fs.BoolVar(&gc.AllowRepoExec, "allow-exec", gc.AllowRepoExec, "Run the external modules set in the repository configuration files")
+	fs.Parse(args)
+
+	if fs.NArg() < 2 {
+		fmt.Println("Usage: goodcommit hook run [flags] <hook> <message file> [source] [sha]")
+		return 1
+	}
+	hook, messageFile := fs.Arg(0), fs.Arg(1)
+
+	switch hook {
+	case "commit-msg":
+		lintArgs := []string{messageFile}
+		if configPath != "" {
+			lintArgs = append([]string{"--config", configPath}, lintArgs...)
+		}
+		return runLint(lintArgs)
+	case "prepare-commit-msg":
+	default:
+		fmt.Printf("Error: unknown hook %q\n", hook)
+		return 1
+	}
+
+	// Merge, squash, -m and amend commits already have a message, leave it alone unless configured otherwise
+	sources := slices.Concat(defaultHookSources, gc.SplitInputList(*on))
+	if !slices.Contains(sources, fs.Arg(2)) {
+		return 0
+	}
+
+	// Git runs hooks without a terminal on stdin, so the form is run against /dev/tty
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "goodcommit: no terminal available, leaving the commit message as is.")
+		return 0
+	}
+	defer tty.Close()
+	os.Stdin, os.Stdout = tty, tty
+
+	modules, err := gc.LoadModules(configPath)
+	if err != nil {
+		fmt.Println("Error occurred while loading configuration:", err)
+		return 1
+	}
+	defer gc.CloseModules(modules)
+	accessible, _ := strconv.ParseBool(os.Getenv("ACCESSIBLE"))
+	draft, resumed := chooseDraft(accessible)
+	defaultCommiter, err := goodcommiter.New()
+	if resumed {
+		defaultCommiter, err = goodcommiter.NewFromCommit(draft.Commit)
+	}
+	if err != nil {
+		fmt.Println("Error occurred while loading commiter:", err)
+		return 1
+	}
+	defaultCommiter.SaveDraft(draft)
+	template, err := gc.LoadTemplate(configPath)
+	if err != nil {
+		fmt.Println("Error occurred while loading the message template:", err)
+		return 1
+	}
+	defaultCommiter.SetTemplate(template)
+	if err := defaultCommiter.LoadModules(modules); err != nil {
+		fmt.Println("Error occurred while loading modules:", err)
+		return 1
+	}
+
+	message, err := gc.New(defaultCommiter).Execute(accessible)
+	if errors.Is(err, huh.ErrUserAborted) {
+		fmt.Println("Commit canceled, the values were kept in a draft.")
+		return 1
+	}
+	if errors.Is(err, gc.ErrAborted) {
+		saveMessage(message, "Commit aborted")
+		return 1
+	}
+	if err != nil {
+		fmt.Println("Error occurred while running goodcommit:", err)
+		return 1
+	}
+	if draft != nil {
+		if err := draft.Drop(); err != nil {
+			fmt.Println("Error dropping draft:", err)
+		}
+	}
+
+	// Keep the comments git wrote (status, scissors line and diff) below the message
+	raw, err := os.ReadFile(messageFile)
+	if err != nil {
+		fmt.Println("Error reading commit message file:", err)
+		return 1
+	}
+	var comments []string
+	for _, line := range strings.Split(string(raw), "\n") {
+		if strings.HasPrefix(line, "#") || len(comments) > 0 {
+			comments = append(comments, line)
+		}
+	}
+	content := message
+	if len(comments) > 0 {
+		content = strings.TrimRight(message, "\n") + "\n\n" + strings.Join(comments, "\n")
+	}
+	if err := os.WriteFile(messageFile, []byte(content), 0644); err != nil {
+		fmt.Println("Error writing commit message file:", err)
+		return 1
+	}
+	return 0
+}
+
+// hooksDir returns the directory git runs hooks from, respecting core.hooksPath.
+func hooksDir() (string, error) {
+	if out, err := exec.Command("git", "config", "--get", "core.hooksPath").Output(); err == nil {
+		dir := strings.TrimSpace(string(out))
+		if dir != "" && !filepath.IsAbs(dir) {
+			top, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+			if err != nil {
+				return "", fmt.Errorf("error finding repository root: %w", err)
+			}
+			dir = filepath.Join(strings.TrimSpace(string(top)), dir)
+		}
+		if dir != "" {
+			return dir, nil
+		}
+	}
+	out, err := exec.Command("git", "rev-parse", "--git-path", "hooks").Output()
+	if err != nil {
+		return "", fmt.Errorf("not in a git repository: %w", err)
+	}
+	return filepath.Abs(strings.TrimSpace(string(out)))
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	gc "github.com/nantli/goodcommit"
+	"github.com/nantli/goodcommit/scopes"
+)
+
+// skippedScopeDirs are the top-level directories that are not turned into scopes.
+var skippedScopeDirs = []string{"node_modules", "vendor", "testdata", "bin", "dist", "build"}
+
+// runInit writes an editable copy of the built-in configuration into the repository: a
+// .goodcommit.json and the types, scopes and co-authors files it references.
+func runInit(args []string) int {
+	flags := flag.NewFlagSet("init", flag.ExitOnError)
+	dir := flags.String("dir", ".goodcommit", "Directory for the types, scopes and co-authors files, relative to the repository root")
+	scopesFromDirs := flags.Bool("scopes-from-dirs", false, "Add a scope for each top-level directory of the repository")
+	force := flags.Bool("force", false, "Overwrite existing files")
+	flags.Parse(args)
+
+	if err := initConfig(*dir, *scopesFromDirs, *force); err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	return 0
+}
+
+// initConfig writes the configuration files, failing if any of them exists unless force is set.
+func initConfig(dir string, scopesFromDirs, force bool) error {
+	root, err := gc.RepoRoot()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := gc.LoadConfig([]gc.ConfigLayer{gc.BuiltinLayer})
+	if err != nil {
+		return err
+	}
+
+	files := make(map[string]any)
+	var modules []gc.ModuleConfig
+	var typeIds []string
+	for _, mc := range cfg.ModulesToActivate {
+		if mc.Name == "types" {
+			// Move the types from the options to their own file
+			var items struct {
+				Types []map[string]any `json:"types"`
+				Rules []map[string]any `json:"rules,omitempty"`
+			}
+			if err := json.Unmarshal(mc.Options, &items); err != nil {
+				return err
+			}
+			for _, t := range items.Types {
+				typeIds = append(typeIds, fmt.Sprint(t["id"]))
+			}
+			mc.Path = filepath.ToSlash(filepath.Join(dir, "commit_types.json"))
+			mc.Options = nil
+			files[mc.Path] = items
+		}
+		// Scopes depend on the selected type, so they get their own page after the types
+		if mc.Page > 1 {
+			mc.Page++
+		}
+		modules = append(modules, mc)
+	}
+
+	var scopes []map[string]any
+	if scopesFromDirs {
+		if scopes, err = dirScopes(root, typeIds); err != nil {
+			return err
+		}
+	}
+	scopesConfig := gc.ModuleConfig{
+		Name:         "scopes",
+		Active:       len(scopes) > 0,
+		Page:         2,
+		Position:     1,
+		Priority:     4,
+		Dependencies: []string{"types"},
+		Path:         filepath.ToSlash(filepath.Join(dir, "commit_scopes.json")),
+	}
+	files[scopesConfig.Path] = map[string]any{"scopes": append([]map[string]any{}, scopes...)}
+
+	lastPage := 0
+	for _, mc := range modules {
+		lastPage = max(lastPage, mc.Page)
+	}
+	coauthorsConfig := gc.ModuleConfig{
+		Name:     "coauthors",
+		Active:   false,
+		Page:     lastPage + 1,
+		Position: 1,
+		Priority: 20,
+		Path:     filepath.ToSlash(filepath.Join(dir, "commit_coauthors.json")),
+	}
+	files[coauthorsConfig.Path] = map[string]any{"coauthors": gitUserCoauthors()}
+
+	modules = append(modules[:1], append([]gc.ModuleConfig{scopesConfig}, modules[1:]...)...)
+	modules = append(modules, coauthorsConfig)
+	files[gc.RepoConfigName] = gc.Config{ModulesToActivate: modules}
+
+	// Check every file before writing any of them
+	names := []string{gc.RepoConfigName, modules[0].Path, scopesConfig.Path, coauthorsConfig.Path}
+	for _, name := range names {
+		if _, err := os.Stat(filepath.Join(root, name)); err == nil && !force {
+			return fmt.Errorf("%s already exists, use --force to overwrite it", name)
+		} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+		return err
+	}
+	for _, name := range names {
+		content, _ := json.MarshalIndent(files[name], "", "    ")
+		if err := os.WriteFile(filepath.Join(root, name), append(content, '\n'), 0o644); err != nil {
+			return err
+		}
+		fmt.Println("Wrote", name)
+	}
+
+	if !scopesConfig.Active {
+		fmt.Printf("Add scopes to %s and set the scopes module active to use them.\n", scopesConfig.Path)
+	}
+	fmt.Printf("Add co-authors to %s and set the coauthors module active to use them.\n", coauthorsConfig.Path)
+	return nil
+}
+
+// dirScopes returns a scope for each top-level directory of the repository, valid for all the types
+// and suggested when the staged files are in the directory.
+func dirScopes(root string, typeIds []string) ([]map[string]any, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var dirs []map[string]any
+	var used []string
+	for _, e := range entries {
+		name := e.Name()
+		if !e.IsDir() || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || slices.Contains(skippedScopeDirs, name) {
+			continue
+		}
+		// Scopes are shown by their emoji in the commit title, so each one gets an unused emoji,
+		// those left without one are shown by their id
+		scope := map[string]any{
+			"id":          name,
+			"name":        name,
+			"description": "Changes to " + name + "/",
+			"conditional": typeIds,
+			"paths":       []string{name + "/**"},
+		}
+		if emoji := scopes.PickEmoji(name, used); emoji != "" {
+			scope["emoji"] = emoji
+			used = append(used, emoji)
+		}
+		dirs = append(dirs, scope)
+	}
+	return dirs, nil
+}
+
+// gitUserCoauthors returns the git user as the first co-author, whose emoji signs the commits.
+func gitUserCoauthors() []map[string]any {
+	name, _ := exec.Command("git", "config", "--get", "user.name").Output()
+	email, _ := exec.Command("git", "config", "--get", "user.email").Output()
+	if len(email) == 0 {
+		return []map[string]any{}
+	}
+	return []map[string]any{{
+		"id":    strings.TrimSpace(string(email)),
+		"name":  strings.TrimSpace(string(name)),
+		"emoji": "🙂",
+	}}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"strings"
+
+	gc "github.com/nantli/goodcommit"
+)
+
+// inputFlag is a flag.Value that stores the values of a module input.
+type inputFlag struct {
+	input  gc.Input
+	values gc.InputValues
+}
+
+func (f *inputFlag) String() string {
+	if f == nil || f.values == nil {
+		return ""
+	}
+	return strings.Join(f.values[f.input.Key], ",")
+}
+
+func (f *inputFlag) Set(value string) error {
+	if f.input.Kind == gc.InputList {
+		f.values[f.input.Key] = append(f.values[f.input.Key], gc.SplitInputList(value)...)
+		return nil
+	}
+	f.values[f.input.Key] = []string{value}
+	return nil
+}
+
+func (f *inputFlag) IsBoolFlag() bool {
+	return f.input.Kind == gc.InputBool
+}
+
+// registerInputFlags defines a flag for each input declared by the modules and
+// returns the inputs along with the values that will be filled when parsing the flags.
+func registerInputFlags(modules []gc.Module) ([]gc.Input, gc.InputValues) {
+	var inputs []gc.Input
+	values := gc.InputValues{}
+	for _, m := range modules {
+		im, ok := m.(gc.InputModule)
+		if !ok {
+			continue
+		}
+		for _, in := range im.Inputs() {
+			if flag.Lookup(in.Key) != nil {
+				continue
+			}
+			f := &inputFlag{input: in, values: values}
+			flag.Var(f, in.Key, in.Usage)
+			if in.Short != "" {
+				flag.Var(f, in.Short, in.Usage+" (shorthand)")
+			}
+			inputs = append(inputs, in)
+		}
+	}
+	return inputs, values
+}
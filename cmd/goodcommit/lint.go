@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	gc "github.com/nantli/goodcommit"
+)
+
+// Exit codes of the lint command, suitable for a commit-msg hook and CI.
+const (
+	lintOK         = 0 // All messages follow the rules.
+	lintViolations = 1 // At least one message does not follow the rules.
+	lintError      = 2 // The messages or the configuration could not be read.
+)
+
+// ignoredPrefixes are the prefixes of messages generated by git that are not linted.
+var ignoredPrefixes = []string{"Merge ", "Revert \"", "fixup! ", "squash! ", "amend! "}
+
+type lintMessage struct {
+	Source string
+	Text   string
+}
+
+type lintResult struct {
+	Source     string         `json:"source"`
+	Header     string         `json:"header"`
+	Violations []gc.Violation `json:"violations"`
+}
+
+// runLint validates existing commit messages against the rules of the active modules.
+// The messages are read from a file, from stdin or from a git revision range.
+func runLint(args []string) int {
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	configPath := os.Getenv("GOODCOMMIT_CONFIG_PATH")
+	fs.StringVar(&configPath, "config", configPath, "Path to a configuration file")
+	revRange := fs.String("range", "", "Lint the messages of the commits in a git revision range, e.g. origin/main..HEAD")
+	format := fs.String("format", "text", "Output format, text or json")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: goodcommit lint [flags] [file]")
+		fmt.Fprintln(fs.Output(), "\nLints the commit message in file, or in stdin if no file or \"-\" is given.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return lintOK
+		}
+		return lintError
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "Error: unknown format %q\n", *format)
+		return lintError
+	}
+
+	messages, err := readLintMessages(fs.Args(), *revRange)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error occurred while reading commit messages:", err)
+		return lintError
+	}
+
+	// The messages may come from anyone, e.g. a pull request, so external modules are not started
+	modules, err := gc.LoadValidators(configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error occurred while loading configuration:", err)
+		return lintError
+	}
+
+	var results []lintResult
+	violations := 0
+	for _, m := range messages {
+		if isIgnoredMessage(m.Text) {
+			continue
+		}
+		header, _, _ := strings.Cut(m.Text, "\n")
+		result := lintResult{Source: m.Source, Header: header, Violations: []gc.Violation{}}
+
+		commit, err := gc.Parse(m.Text)
+		if err != nil {
+			result.Violations = append(result.Violations, gc.Violation{Field: "header", Message: err.Error()})
+		} else {
+			result.Violations = append(result.Violations, gc.Validate(modules, &commit)...)
+		}
+		violations += len(result.Violations)
+		results = append(results, result)
+	}
+
+	if *format == "json" {
+		out, _ := json.MarshalIndent(results, "", "    ")
+		fmt.Println(string(out))
+	} else {
+		for _, r := range results {
+			for _, v := range r.Violations {
+				fmt.Printf("%s: %s\n", r.Source, v)
+			}
+		}
+		if violations > 0 {
+			fmt.Printf("\n%d violation(s) found in %d message(s).\n", violations, len(results))
+		}
+	}
+
+	if violations > 0 {
+		return lintViolations
+	}
+	return lintOK
+}
+
+// readLintMessages reads the messages to lint from the given git revision range,
+// or else from the file in args or from stdin.
+func readLintMessages(args []string, revRange string) ([]lintMessage, error) {
+	if revRange != "" {
+		out, err := exec.Command("git", "log", "--no-merges", "--format=%h%x1f%B%x1e", revRange).Output()
+		if err != nil {
+			return nil, fmt.Errorf("error running git log: %w", err)
+		}
+		var messages []lintMessage
+		for _, entry := range strings.Split(string(out), "\x1e") {
+			hash, text, found := strings.Cut(strings.TrimSpace(entry), "\x1f")
+			if found {
+				messages = append(messages, lintMessage{Source: hash, Text: strings.TrimSpace(text)})
+			}
+		}
+		return messages, nil
+	}
+
+	if len(args) == 0 || args[0] == "-" {
+		raw, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		return []lintMessage{{Source: "stdin", Text: cleanMessage(string(raw))}}, nil
+	}
+
+	raw, err := os.ReadFile(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return []lintMessage{{Source: args[0], Text: cleanMessage(string(raw))}}, nil
+}
+
+// cleanMessage removes the comments and the diff below the scissors line that git
+// adds to the message file before passing it to the commit-msg hook.
+func cleanMessage(message string) string {
+	var lines []string
+	for _, line := range strings.Split(message, "\n") {
+		if strings.HasPrefix(line, "# ------------------------ >8 ------------------------") {
+			break
+		}
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func isIgnoredMessage(message string) bool {
+	for _, prefix := range ignoredPrefixes {
+		if strings.HasPrefix(message, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	gc "github.com/nantli/goodcommit"
+	"github.com/nantli/goodcommit/goodcommiter"
+)
+
+// lintConfig has scopes whose names have spaces, which the "SCOPE:" header must keep together.
+const lintConfig = `{
+    "activeModules": [
+        {"name": "types", "active": true, "page": 1, "position": 1, "options": {"types": [
+            {"id": "feat", "name": "Feat", "emoji": "🌟"},
+            {"id": "docs", "name": "Docs", "emoji": "📚"}
+        ]}},
+        {"name": "scopes", "active": true, "page": 2, "position": 1, "priority": 4, "options": {"scopes": [
+            {"id": "build", "name": "Build System", "emoji": "🏗️", "conditional": ["feat"]},
+            {"id": "cli", "name": "Command Line", "emoji": "💻", "conditional": ["feat"]},
+            {"id": "site", "name": "Site", "emoji": "🌐", "conditional": ["*"]}
+        ]}},
+        {"name": "description", "active": true, "page": 3, "position": 1},
+        {"name": "why", "active": true, "page": 3, "position": 2, "priority": 3},
+        {"name": "body", "active": true, "page": 3, "position": 3, "priority": 2},
+        {"name": "breaking", "active": true, "page": 3, "position": 4, "priority": 5},
+        {"name": "breakingmsg", "active": true, "page": 4, "position": 1, "priority": 6}
+    ]
+}`
+
+// TestLintRenderedMessages checks that the messages rendered by goodcommit pass the lint of the
+// same configuration, as the commit-msg hook would otherwise block the commits of the tool.
+func TestLintRenderedMessages(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	configPath := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(configPath, []byte(lintConfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		values gc.InputValues
+	}{
+		{"scope with spaces", gc.InputValues{"type": {"feat"}, "scope": {"build"}, "description": {"add thing"}}},
+		{"scopes with spaces", gc.InputValues{"type": {"feat"}, "scope": {"build", "cli", "site"}, "description": {"add thing"}}},
+		{"no scope", gc.InputValues{"type": {"docs"}, "description": {"add thing"}, "body": {"Some body."}}},
+		{"everything", gc.InputValues{
+			"type": {"feat"}, "scope": {"cli", "build"}, "description": {"add thing"}, "why": {"It was missing."},
+			"body": {"Some body."}, "breaking": {"true"}, "breaking-message": {"Gone."},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modules, err := gc.LoadModules(configPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			commiter, err := goodcommiter.New()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := commiter.LoadModules(modules); err != nil {
+				t.Fatal(err)
+			}
+			message, err := gc.New(commiter).ExecuteHeadless(tt.values)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			file := filepath.Join(dir, "COMMIT_EDITMSG")
+			if err := os.WriteFile(file, []byte(message+"\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if code := runLint([]string{"--config", configPath, file}); code != lintOK {
+				t.Errorf("lint of the rendered message exited with %d, want %d:\n%s", code, lintOK, message)
+			}
+		})
+	}
+}
+
+// TestLintSpaceSeparatedScopes checks that the scope names of older messages, separated by
+// spaces, are resolved from the emojis of the title.
+func TestLintSpaceSeparatedScopes(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	configPath := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(configPath, []byte(lintConfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		message string
+		want    int
+	}{
+		{"feat(🏗️💻): add thing\n\nSCOPES: Build System Command Line\n", lintOK},
+		{"docs(🏗️): add thing\n\nSCOPES: Build System\n", lintViolations},
+		{"feat(🚧): add thing\n\nSCOPES: Road Works\n", lintViolations},
+	}
+	for _, tt := range tests {
+		file := filepath.Join(dir, "COMMIT_EDITMSG")
+		if err := os.WriteFile(file, []byte(tt.message), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if code := runLint([]string{"--config", configPath, file}); code != tt.want {
+			t.Errorf("lint of %q exited with %d, want %d", tt.message, code, tt.want)
+		}
+	}
+}
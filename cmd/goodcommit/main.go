@@ -4,22 +4,52 @@ It is designed to be highly configurable and extensible, allowing for a wide ran
 
 Usage:
 
-	goodcommit [flags]
+	goodcommit [flags] [-- git commit flags]
+	goodcommit lint [flags] [file]
+	goodcommit hook install|uninstall [--type prepare-commit-msg|commit-msg] [--on sources] [--config path]
+	goodcommit init [--scopes-from-dirs] [--dir path] [--force]
+	goodcommit drafts list|show <id>|drop [--all] <id>...
+	goodcommit plugin test [--options json] [--commit file] [--set key=value] <command>
+	goodcommit config show [--origin] [--config path]
+	goodcommit config validate [--config path]
+	goodcommit config schema [--out dir] [module]
 
 Flags:
 
 	--accessible        Enable accessible mode
 	--config            Path to a configuration file
-	--retry         Retry commit with the last saved commit message
-	--edit          Edit the last saved commit message
+	--retry[=id]    Commit a saved commit message, picked from a list if no id is given
+	--edit[=id]     Edit a saved commit message, picked from a list if no id is given
+	--non-interactive   Fill the commit from flags, --from-json or GOODCOMMIT_* variables
+	--from-json     Path to a JSON file with the commit values ("-" reads stdin)
 	-m              Dry run mode, do not execute commit
 	-h              Show this help message
+
+Flags passed through to git commit (more can be given after "--"):
+
+	--amend         Amend the last commit, the form starts filled with its message
+	--no-verify     Bypass the pre-commit and commit-msg hooks
+	-S              GPG-sign the commit
+	--allow-empty   Allow a commit without changes
+
+Module flags (only used in non-interactive mode):
+
+	-t, --type          Commit type id
+	-s, --scope         Commit scope ids, can be repeated or comma separated
+	-d, --description   Brief description of the commit
+	-b, --body          Detailed description of the changes
+	--why               Reason for the change
+	--breaking          Mark the commit as introducing a breaking change
+	--breaking-message  Details about the breaking changes
+	--coauthor          Co-author ids, can be repeated or comma separated
 */
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strconv"
@@ -27,22 +57,52 @@ import (
 
 	"github.com/charmbracelet/huh"
 	gc "github.com/nantli/goodcommit"
-	"github.com/nantli/goodcommit/body"
-	"github.com/nantli/goodcommit/breaking"
-	"github.com/nantli/goodcommit/breakingmsg"
-	"github.com/nantli/goodcommit/coauthors"
-	"github.com/nantli/goodcommit/description"
 	"github.com/nantli/goodcommit/goodcommiter"
-	"github.com/nantli/goodcommit/greetings"
-	"github.com/nantli/goodcommit/logo"
-	"github.com/nantli/goodcommit/scopes"
-	"github.com/nantli/goodcommit/signedoffby"
-	"github.com/nantli/goodcommit/types"
-	"github.com/nantli/goodcommit/why"
+
+	// Modules register themselves in the goodcommit registry, import your own to use them
+	_ "github.com/nantli/goodcommit/body"
+	_ "github.com/nantli/goodcommit/breaking"
+	_ "github.com/nantli/goodcommit/breakingmsg"
+	_ "github.com/nantli/goodcommit/coauthors"
+	_ "github.com/nantli/goodcommit/description"
+	_ "github.com/nantli/goodcommit/greetings"
+	_ "github.com/nantli/goodcommit/logo"
+	_ "github.com/nantli/goodcommit/scopes"
+	_ "github.com/nantli/goodcommit/signedoffby"
+	_ "github.com/nantli/goodcommit/tickets"
+	_ "github.com/nantli/goodcommit/types"
+	_ "github.com/nantli/goodcommit/why"
 )
 
+// registeredModules returns a new instance of every registered module.
+func registeredModules() []gc.Module {
+	var modules []gc.Module
+	for _, name := range gc.Registered() {
+		modules = append(modules, gc.NewRegistered(name))
+	}
+	return modules
+}
+
 func main() {
 
+	// Run subcommands
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "lint":
+			os.Exit(runLint(os.Args[2:]))
+		case "hook":
+			os.Exit(runHook(os.Args[2:]))
+		case "config":
+			os.Exit(runConfig(os.Args[2:]))
+		case "init":
+			os.Exit(runInit(os.Args[2:]))
+		case "plugin":
+			os.Exit(runPlugin(os.Args[2:]))
+		case "drafts":
+			os.Exit(runDrafts(os.Args[2:]))
+		}
+	}
+
 	// Get configuration path from environment variable or flag
 	configPath := os.Getenv("GOODCOMMIT_CONFIG_PATH")
 	flag.StringVar(&configPath, "config", configPath, "Path to a configuration file")
@@ -51,56 +111,93 @@ func main() {
 	accessible, _ := strconv.ParseBool(os.Getenv("ACCESSIBLE"))
 	flag.BoolVar(&accessible, "accessible", accessible, "Enable accessible mode")
 
-	// Get dry-run, retry, help and edit options from flags
+	// Get the opt-in to run the external modules of the repository from environment variable or flag
+	gc.AllowRepoExec, _ = strconv.ParseBool(os.Getenv("GOODCOMMIT_ALLOW_EXEC"))
+	flag.BoolVar(&gc.AllowRepoExec, "allow-exec", gc.AllowRepoExec, "Run the external modules set in the repository configuration files")
+
+	// Get dry-run, retry, help and edit options from flags, retry and edit take an optional id
 	dryRun := flag.Bool("m", false, "Dry run mode, do not execute commit")
-	retry := flag.Bool("retry", false, "Retry commit with the last saved commit message")
 	help := flag.Bool("h", false, "Show this help message")
-	edit := flag.Bool("edit", false, "Edit the last saved commit message")
+	var retry, edit optionalValue
+	flag.Var(&retry, "retry", "Commit a saved commit message, `id` is picked from a list if not given")
+	flag.Var(&edit, "edit", "Edit a saved commit message, `id` is picked from a list if not given")
+
+	// Get non-interactive options from environment variable or flags
+	nonInteractive, _ := strconv.ParseBool(os.Getenv("GOODCOMMIT_NON_INTERACTIVE"))
+	flag.BoolVar(&nonInteractive, "non-interactive", nonInteractive, "Fill the commit from flags, --from-json or GOODCOMMIT_* variables")
+	fromJSON := flag.String("from-json", "", "Path to a JSON file with the commit values (\"-\" reads stdin)")
+
+	// Get the options passed through to git commit from flags, extra ones can be given after "--"
+	amend := flag.Bool("amend", false, "Amend the last commit, starting from its message")
+	noVerify := flag.Bool("no-verify", false, "Bypass the pre-commit and commit-msg hooks (passed to git commit)")
+	signCommit := flag.Bool("S", false, "GPG-sign the commit (passed to git commit)")
+	allowEmpty := flag.Bool("allow-empty", false, "Allow a commit without changes (passed to git commit)")
+
+	// Each module input can also be given as a flag
+	// The flags of the module inputs are registered before the configuration is known
+	inputs, flagValues := registerInputFlags(registeredModules())
 	flag.Parse()
 
+	// Build the executor that runs git commit with the passthrough flags
+	var commitArgs []string
+	if *amend {
+		commitArgs = append(commitArgs, "--amend")
+	}
+	if *noVerify {
+		commitArgs = append(commitArgs, "--no-verify")
+	}
+	if *signCommit {
+		commitArgs = append(commitArgs, "-S")
+	}
+	if *allowEmpty {
+		commitArgs = append(commitArgs, "--allow-empty")
+	}
+	executor := gc.NewGitExecutor(append(commitArgs, flag.Args()...)...)
+
 	// Show help message if -h flag is set
 	if *help {
 		flag.Usage()
 		os.Exit(0)
 	}
 
-	// If the --edit flag is set, open the editor with the temporary commit message file (previously saved on .goodcommit_msg.tmp, after an errored run)
-	if *edit {
-		editor := os.Getenv("EDITOR")
-		if editor == "" {
-			editor = "vim" // Default to vim if EDITOR env var is not set
-		}
-
-		// Construct the command to open the editor with the temporary commit message file
-		cmd := exec.Command(editor, ".goodcommit_msg.tmp")
-		cmd.Stdin = os.Stdin
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
+	// Saved messages and drafts of deleted branches are no longer needed
+	_, _ = gc.Prune()
 
-		err := cmd.Run()
+	// If the --edit flag is set, open the editor with a saved commit message (saved after an errored or aborted run)
+	if edit.set {
+		saved, err := pickSavedMessage(edit.value, accessible)
 		if err != nil {
-			fmt.Printf("Error opening editor: %s\n", err)
+			fmt.Printf("Error reading saved commit message: %s\n", err)
+			os.Exit(1)
+		}
+		saved.Message, err = gc.EditMessage(saved.Message)
+		if err != nil {
+			fmt.Printf("Error editing commit message: %s\n", err)
+			os.Exit(1)
+		}
+		if err := saved.Save(); err != nil {
+			fmt.Printf("Error saving commit message: %s\n", err)
 			os.Exit(1)
 		}
 
-		fmt.Println("Commit message edited, now run 'goodcommit --retry' to commit.")
+		fmt.Printf("Commit message edited, now run 'goodcommit --retry=%s' to commit.\n", saved.ID)
 		os.Exit(0)
 	}
 
 	// Ensure -m and --retry flags are not used together
-	if *retry && *dryRun {
+	if retry.set && *dryRun {
 		fmt.Println("Error: -m and --retry cannot be used together.")
 		os.Exit(1)
 	}
 
-	// If the --retry flag is used, read the commit message from the temporary file (.goodcommit_msg.tmp) and execute the commit
-	if *retry {
-		messageBytes, err := os.ReadFile(".goodcommit_msg.tmp")
+	// If the --retry flag is used, commit a saved commit message
+	if retry.set {
+		saved, err := pickSavedMessage(retry.value, accessible)
 		if err != nil {
 			fmt.Printf("Error reading saved commit message: %s\n", err)
 			os.Exit(1)
 		}
-		message := string(messageBytes)
+		message := saved.Message
 
 		// Show the commit message and ask for confirmation
 		var confirm bool
@@ -116,22 +213,19 @@ func main() {
 		}
 
 		if confirm {
-			cmdStr := fmt.Sprintf("git commit -m \"%s\"", strings.ReplaceAll(message, "\"", "\\\""))
-			cmd := exec.Command("sh", "-c", cmdStr)
-
-			// Run the command and capture the combined stdout and stderr
+			// Run the commit and capture the combined stdout and stderr
 			// so that user can see possible errors outputed to those from git hooks for example.
-			output, err := cmd.CombinedOutput()
+			output, err := executor.Commit(message)
 			if err != nil {
 				fmt.Printf("Error executing commit command: %s\nOutput:\n%s\n", err, output)
 				os.Exit(1)
 			}
-			fmt.Println("Commit successful with the last saved commit message.")
+			fmt.Println("Commit successful with the saved commit message.")
 
-			// Remove the temporary file now that the changes are committed
-			err = os.Remove(".goodcommit_msg.tmp")
+			// Remove the saved message now that the changes are committed
+			err = saved.Drop()
 			if err != nil {
-				fmt.Printf("Error removing temporary file: %s\n", err)
+				fmt.Printf("Error removing saved commit message: %s\n", err)
 			}
 		} else {
 			fmt.Println("Commit canceled.")
@@ -141,65 +235,129 @@ func main() {
 
 	// Otherwhise start the usual goodcommit flow
 
-	// Load modules
-	modules := []gc.Module{
-		logo.New(),
-		greetings.New(),
-		types.New(),
-		scopes.New(),
-		body.New(),
-		why.New(),
-		description.New(),
-		breaking.New(),
-		breakingmsg.New(),
-		coauthors.New(),
-		signedoffby.New(),
-	}
-
-	// Update modules with configuration
-	modules, err := gc.LoadConfigToModules(modules, configPath)
+	// Create the modules of the configuration
+	modules, err := gc.LoadModules(configPath)
 	if err != nil {
 		fmt.Println("Error occurred while loading configuration:", err)
 		os.Exit(1)
 	}
 
-	// Load the modules to the default commiter
+	// External modules keep running until goodcommit is done with them
+	exit := func(code int) {
+		gc.CloseModules(modules)
+		os.Exit(code)
+	}
+
+	// The form is saved to a draft as it is filled, the last draft of the branch can be resumed
+	interactive := !nonInteractive && *fromJSON == ""
+	var draft *gc.Draft
+	resumed := false
+	if interactive && !*amend {
+		draft, resumed = chooseDraft(accessible)
+	}
+
+	// Load the modules to the default commiter, starting from the message of HEAD when amending
 	defaultCommiter, err := goodcommiter.New()
+	if *amend {
+		defaultCommiter, err = goodcommiter.NewFromCommit(headCommit())
+	} else if resumed {
+		defaultCommiter, err = goodcommiter.NewFromCommit(draft.Commit)
+	}
 	if err != nil {
 		fmt.Println("Error occurred while loading commiter:", err)
-		os.Exit(1)
+		exit(1)
 	}
+	defaultCommiter.SaveDraft(draft)
+	template, err := gc.LoadTemplate(configPath)
+	if err != nil {
+		fmt.Println("Error occurred while loading the message template:", err)
+		exit(1)
+	}
+	defaultCommiter.SetTemplate(template)
 	err = defaultCommiter.LoadModules(modules)
 	if err != nil {
 		fmt.Println("Error occurred while loading modules:", err)
-		os.Exit(1)
+		exit(1)
 	}
 
-	// Load and execute goodcommit
+	// Load and execute goodcommit, filling the commit from the given values if not interactive
 	goodcommit := gc.New(defaultCommiter)
-	message, err := goodcommit.Execute(accessible)
+	var message string
+	if !interactive {
+		values := gc.EnvInputValues(inputs)
+		if *fromJSON != "" {
+			jsonValues, err := readInputValues(*fromJSON)
+			if err != nil {
+				fmt.Println("Error occurred while reading commit values:", err)
+				exit(1)
+			}
+			values.Merge(jsonValues)
+		}
+		values.Merge(flagValues)
+		message, err = goodcommit.ExecuteHeadless(values)
+	} else {
+		message, err = goodcommit.Execute(accessible)
+	}
+	if errors.Is(err, gc.ErrAborted) {
+		// Keep the message, to commit it later with --retry
+		saveMessage(message, "Commit aborted")
+		exit(1)
+	}
 	if err != nil {
 		fmt.Println("Error occurred while running goodcommit:", err)
-		os.Exit(1)
+		if draft != nil {
+			fmt.Println("The values were kept in a draft, run 'goodcommit' again to resume it.")
+		}
+		exit(1)
+	}
+
+	// The message is complete, if the commit fails it is saved for --retry instead
+	if draft != nil {
+		if err := draft.Drop(); err != nil {
+			fmt.Println("Error dropping draft:", err)
+		}
 	}
 
 	// Commit changes, execute command if not in dry run mode
-	if !*dryRun && !*retry {
-		cmdStr := fmt.Sprintf("git commit -m \"%s\"", strings.ReplaceAll(message, "\"", "\\\""))
-		cmd := exec.Command("sh", "-c", cmdStr)
-		// Run the command and capture the combined stdout and stderr
-		output, err := cmd.CombinedOutput()
-		if err != nil {
-			// Save commit message to temporary file on error
-			errSave := os.WriteFile(".goodcommit_msg.tmp", []byte(message), 0644)
-			if errSave != nil {
-				fmt.Printf("Error saving commit message ('goodcommit --retry' won't work 😢): %s\n", errSave)
-			}
-			// Print the combined stdout and stderr to give feedback to the user
-			fmt.Printf("Error executing command: %s\nOutput:\n%s\n", err, output)
-			os.Exit(1)
+	if !*dryRun {
+		if !commitMessage(executor, message) {
+			exit(1)
 		}
 	} else if *dryRun {
+		if !interactive {
+			fmt.Println(message)
+		}
 		fmt.Println("Dry run mode, commit not executed.")
 	}
+	gc.CloseModules(modules)
+}
+
+// headCommit returns the parsed message of HEAD, to pre-fill the form when amending.
+// If the message can't be parsed the form starts blank.
+func headCommit() gc.Commit {
+	commit := gc.Commit{Extras: make(map[string]*string)}
+	out, err := exec.Command("git", "log", "-1", "--format=%B").Output()
+	if err != nil {
+		fmt.Println("Error reading the last commit message, starting from a blank commit:", err)
+	} else if commit, err = gc.Parse(strings.TrimSpace(string(out))); err != nil {
+		fmt.Println("Error parsing the last commit message, starting from a blank commit:", err)
+		commit = gc.Commit{Extras: make(map[string]*string)}
+	}
+	commit.Amend = true
+	return commit
+}
+
+// readInputValues reads the commit values from a JSON file, or from stdin if path is "-".
+func readInputValues(path string) (gc.InputValues, error) {
+	var raw []byte
+	var err error
+	if path == "-" {
+		raw, err = io.ReadAll(os.Stdin)
+	} else {
+		raw, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return gc.ParseInputValues(raw)
 }
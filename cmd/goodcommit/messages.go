@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/charmbracelet/huh"
+	gc "github.com/nantli/goodcommit"
+)
+
+// optionalValue is a flag that can be given alone, like a boolean flag, or with a value, e.g.
+// --retry and --retry=20240102-150405.
+type optionalValue struct {
+	set   bool
+	value string
+}
+
+func (v *optionalValue) String() string {
+	return v.value
+}
+
+func (v *optionalValue) Set(s string) error {
+	v.set = true
+	if s != "true" {
+		v.value = s
+	}
+	return nil
+}
+
+func (v *optionalValue) IsBoolFlag() bool {
+	return true
+}
+
+// saveMessage saves a message that could not be committed and tells the user how to commit it.
+func saveMessage(message string, reason string) {
+	saved, err := gc.SaveMessage(message)
+	if err != nil {
+		fmt.Printf("%s, and the message could not be saved ('goodcommit --retry' won't work 😢): %s\n", reason, err)
+		return
+	}
+	fmt.Printf("%s, the message was saved as %s. Run 'goodcommit --edit' to change it or 'goodcommit --retry' to commit it.\n", reason, saved.ID)
+}
+
+// commitMessage commits the message with the executor. If the commit fails, e.g. because a hook
+// rejects it, the output is shown and the message is saved for --retry. It tells if the commit
+// was created.
+func commitMessage(executor gc.CommitExecutor, message string) bool {
+	// Capture the combined stdout and stderr to give feedback to the user
+	output, err := executor.Commit(message)
+	if err != nil {
+		fmt.Printf("Error executing command: %s\nOutput:\n%s\n", err, output)
+		saveMessage(message, "Commit failed")
+		return false
+	}
+	return true
+}
+
+// pickSavedMessage returns the saved message with the given id or, without an id, the one the
+// user picks from the saved messages. There is nothing to pick when only one message is saved.
+func pickSavedMessage(id string, accessible bool) (*gc.SavedMessage, error) {
+	if id != "" {
+		return gc.LoadSavedMessage(id)
+	}
+	messages, err := gc.SavedMessages()
+	if err != nil {
+		return nil, err
+	}
+	switch len(messages) {
+	case 0:
+		return nil, errors.New("no saved commit message")
+	case 1:
+		return messages[0], nil
+	}
+
+	var options []huh.Option[*gc.SavedMessage]
+	for _, m := range messages {
+		label := fmt.Sprintf("%s  %s  %s", m.Saved.Format("Jan 2 15:04"), m.Branch, m.Title())
+		options = append(options, huh.NewOption(label, m))
+	}
+	var picked *gc.SavedMessage
+	err = huh.NewSelect[*gc.SavedMessage]().
+		Title("📜・Pick a Saved Message").
+		Description("Messages of the current branch first.").
+		Options(options...).
+		Value(&picked).
+		WithTheme(huh.ThemeCharm()).
+		WithAccessible(accessible).
+		Run()
+	return picked, err
+}
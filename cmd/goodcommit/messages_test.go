@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"testing"
+
+	gc "github.com/nantli/goodcommit"
+)
+
+// fakeExecutor records the messages it is given instead of committing them.
+type fakeExecutor struct {
+	messages []string
+	err      error
+}
+
+func (e *fakeExecutor) Commit(message string) ([]byte, error) {
+	e.messages = append(e.messages, message)
+	if e.err != nil {
+		return []byte("hook rejected the commit"), e.err
+	}
+	return nil, nil
+}
+
+// TestCommitMessage checks that the message is handed to the executor as rendered, and saved
+// for --retry when the commit fails.
+func TestCommitMessage(t *testing.T) {
+	dir := t.TempDir()
+	if out, err := exec.Command("git", "init", "-q", dir).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v: %s", err, out)
+	}
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	message := "feat: add `thing` with $(echo) and \\n\n\nBody."
+	ok := &fakeExecutor{}
+	if !commitMessage(ok, message) {
+		t.Error("commitMessage() = false, want true")
+	}
+	if len(ok.messages) != 1 || ok.messages[0] != message {
+		t.Errorf("executor got %q, want %q", ok.messages, message)
+	}
+	if saved, _ := gc.SavedMessages(); len(saved) != 0 {
+		t.Errorf("%d messages saved after a successful commit", len(saved))
+	}
+
+	failing := &fakeExecutor{err: errors.New("exit status 1")}
+	if commitMessage(failing, message) {
+		t.Error("commitMessage() = true with a failing executor")
+	}
+	saved, err := gc.SavedMessages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 1 || saved[0].Message != message {
+		t.Errorf("saved messages = %+v, want %q", saved, message)
+	}
+}
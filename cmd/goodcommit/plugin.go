@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	gc "github.com/nantli/goodcommit"
+	"github.com/nantli/goodcommit/goodcommiter"
+	"github.com/nantli/goodcommit/plugin"
+)
+
+// setFlags collects the repeated --set key=value flags.
+type setFlags map[string]string
+
+func (s setFlags) String() string {
+	return fmt.Sprint(map[string]string(s))
+}
+
+func (s setFlags) Set(value string) error {
+	key, v, ok := strings.Cut(value, "=")
+	if !ok {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+	s[key] = v
+	return nil
+}
+
+// runPlugin handles the "goodcommit plugin" commands.
+func runPlugin(args []string) int {
+	if len(args) == 0 || args[0] != "test" {
+		fmt.Println("Usage: goodcommit plugin test [--options json] [--commit file] [--set key=value] <command>")
+		return 1
+	}
+
+	fs := flag.NewFlagSet("plugin test", flag.ExitOnError)
+	name := fs.String("name", "plugin", "Name of the module")
+	options := fs.String("options", "", "Options of the module, in JSON")
+	commitPath := fs.String("commit", "", "JSON file with the initial commit")
+	values := setFlags{}
+	fs.Var(values, "set", "Value entered in the field, as key=value (can be repeated)")
+	fs.Parse(args[1:])
+	if fs.NArg() != 1 {
+		fmt.Println("Error: the command of the module is required")
+		return 1
+	}
+
+	config := gc.ModuleConfig{Name: *name, Active: true, Exec: fs.Arg(0), Options: json.RawMessage(*options)}
+	commit := gc.Commit{}
+	if *commitPath != "" {
+		raw, err := os.ReadFile(*commitPath)
+		if err == nil {
+			err = json.Unmarshal(raw, &commit)
+		}
+		if err != nil {
+			fmt.Println("Error reading the commit:", err)
+			return 1
+		}
+	}
+
+	if err := plugin.Run(config, &commit, values, os.Stdout); err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+
+	commiter, _ := goodcommiter.NewFromCommit(commit)
+	message, err := commiter.RenderMessage()
+	if err != nil {
+		fmt.Println("Error:", err)
+		return 1
+	}
+	fmt.Printf("\n%s\n", message)
+	return 0
+}
@@ -1,12 +1,10 @@
 // Package coauthors provides a github.com/nantli/goodcommit module for selecting co-authors.
 // It presents the user with a multi-select field for selecting co-authors from a predefined list.
-// The selected co-authors are then added to the commit body.
+// The selected co-authors are then added to the commit trailers, and their emojis to the commit body.
 package coauthors
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
 	"os/exec"
 	"strings"
 
@@ -38,7 +36,8 @@ func (c *coAuthors) item(id string) item {
 	return item{}
 }
 
-// LoadConfig loads the co-authors configuration file.
+// LoadConfig loads the co-authors configuration file, and the co-authors in the module options,
+// which are written in the same format.
 // Example config file:
 //
 //	{
@@ -51,22 +50,25 @@ func (c *coAuthors) item(id string) item {
 //	    ]
 //	}
 func (c *coAuthors) LoadConfig() error {
-	if c.config.Path == "" {
-		return nil
+	if c.config.Path != "" {
+		if err := gc.ReadConfigFile(c.config.Path, c); err != nil {
+			return err
+		}
 	}
 
-	raw, err := os.ReadFile(c.config.Path)
-	if err != nil {
-		return fmt.Errorf("error reading config: %w", err)
-	}
-	err = json.Unmarshal(raw, c)
-	if err != nil {
-		return fmt.Errorf("error parsing config: %w", err)
+	options := coAuthors{}
+	if err := c.config.DecodeOptions(&options); err != nil {
+		return err
 	}
-
+	c.Items = gc.MergeOptions(c.Items, options.Items, func(i item) string { return i.Id })
 	return nil
 }
 
+// Schema returns the JSON Schema of the co-authors configuration file.
+func (c *coAuthors) Schema() gc.Schema {
+	return gc.NewSchema("goodcommit co-authors", coAuthors{})
+}
+
 // NewField returns a huh.MultiSelect field with options for each co-author.
 // The commit author is excluded from the list of co-authors.
 func (c *coAuthors) NewField(commit *gc.Commit) (huh.Field, error) {
@@ -87,14 +89,19 @@ func (c *coAuthors) NewField(commit *gc.Commit) (huh.Field, error) {
 		}
 	}
 
-	if len(coAuthors) == 0 {
-		return nil, nil
-	}
-
 	var coAuthorOptions []huh.Option[string]
 	for _, item := range coAuthors {
 		coAuthorOptions = append(coAuthorOptions, huh.NewOption(item.Name+" - "+item.Id, item.Id))
 	}
+	// The co-authors of an amended commit that are not in the list are kept as they were written
+	for _, coAuthor := range commit.CoAuthoredBy {
+		if c.item(coAuthor).Id == "" {
+			coAuthorOptions = append(coAuthorOptions, huh.NewOption(coAuthor, coAuthor))
+		}
+	}
+	if len(coAuthorOptions) == 0 {
+		return nil, nil
+	}
 
 	return huh.NewMultiSelect[string]().
 		Title("👥・Select Co-Authors").
@@ -103,18 +110,37 @@ func (c *coAuthors) NewField(commit *gc.Commit) (huh.Field, error) {
 		Value(&commit.CoAuthoredBy), nil
 }
 
-func (c *coAuthors) PostProcess(commit *gc.Commit) error {
-	// Build the co-authors string
-	coAuthors := commit.CoAuthoredBy
-	for i, coAuthor := range coAuthors {
-		coAuthors[i] = c.item(coAuthor).Name + " <" + c.item(coAuthor).Id + ">"
-	}
-	commit.CoAuthoredBy = coAuthors
+// Inputs lets the co-authors be given with --coauthor when running without a form.
+func (c *coAuthors) Inputs() []gc.Input {
+	return []gc.Input{{
+		Key:   "coauthor",
+		Kind:  gc.InputList,
+		Usage: "Co-author ids, can be repeated or comma separated",
+		Set: func(commit *gc.Commit, values []string) error {
+			for _, v := range values {
+				if c.item(v).Id == "" {
+					return fmt.Errorf("unknown co-author: %s", v)
+				}
+			}
+			commit.CoAuthoredBy = values
+			return nil
+		},
+	}}
+}
 
-	// Sign the commit body with the author and co-authors Emojis
+// PostProcess adds a "Co-authored-by" trailer for each co-author, and signs the commit body with
+// the emojis of the author and co-authors. CoAuthoredBy keeps the ids of the co-authors, and the
+// trailers of those that are not in the list (see InitCommitInfo) are added as they were.
+func (c *coAuthors) PostProcess(commit *gc.Commit) error {
 	emojis := []string{}
-	for _, coAuthor := range coAuthors {
-		emojis = append(emojis, c.item(coAuthor).Emoji)
+	for _, coAuthor := range commit.CoAuthoredBy {
+		i := c.item(coAuthor)
+		if i.Id == "" {
+			commit.AddTrailer("Co-authored-by", coAuthor)
+			continue
+		}
+		commit.AddTrailer("Co-authored-by", i.Name+" <"+i.Id+">")
+		emojis = append(emojis, i.Emoji)
 	}
 
 	emailCmd := exec.Command("git", "config", "--get", "user.email")
@@ -124,10 +150,18 @@ func (c *coAuthors) PostProcess(commit *gc.Commit) error {
 	}
 	authorId := strings.TrimSpace(string(email))
 
-	commit.Body = commit.Body + "\n\n" + c.item(authorId).Emoji + " " + strings.Join(emojis, " ")
+	signature := c.item(authorId).Emoji + " " + strings.Join(emojis, " ")
+	commit.Extras["signature"] = &signature
 	return nil
 }
 
+// Reset removes the co-authors when the field is no longer shown.
+func (c *coAuthors) Reset(commit *gc.Commit, shown bool) {
+	if !shown {
+		commit.CoAuthoredBy = nil
+	}
+}
+
 func (c *coAuthors) Config() gc.ModuleConfig {
 	return c.config
 }
@@ -137,11 +171,23 @@ func (c *coAuthors) SetConfig(config gc.ModuleConfig) {
 }
 
 func (c *coAuthors) Name() string {
-	return MODULE_NAME
+	return c.config.Name
 }
 
+// InitCommitInfo maps the co-authors of an already rendered commit (e.g. when amending) from
+// "Name <id>" back to their ids, so they are selected in the field. Those that are not in the
+// list are kept as "Name <email>", so their trailers are not lost. The trailers and the emojis
+// line are added again in PostProcess.
 func (c *coAuthors) InitCommitInfo(commit *gc.Commit) error {
-	// No initialization of the commit is done by this module
+	for i, coAuthor := range commit.CoAuthoredBy {
+		if start, end := strings.LastIndex(coAuthor, "<"), strings.LastIndex(coAuthor, ">"); start >= 0 && end > start {
+			if id := coAuthor[start+1 : end]; c.item(id).Id != "" {
+				commit.CoAuthoredBy[i] = id
+			}
+		}
+	}
+	commit.RemoveTrailers("Co-authored-by")
+	delete(commit.Extras, "signature")
 	return nil
 }
 
@@ -154,3 +200,7 @@ func (c *coAuthors) IsActive() bool {
 func New() gc.Module {
 	return &coAuthors{config: gc.ModuleConfig{Name: MODULE_NAME}}
 }
+
+func init() {
+	gc.Register(MODULE_NAME, New)
+}
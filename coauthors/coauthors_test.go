@@ -0,0 +1,52 @@
+package coauthors
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"testing"
+
+	gc "github.com/nantli/goodcommit"
+)
+
+// TestAmendUnknownCoAuthor checks that amending a commit keeps the trailers of the co-authors
+// that are not in the list as they were, and maps the others back to their ids.
+func TestAmendUnknownCoAuthor(t *testing.T) {
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "user.email")
+	t.Setenv("GIT_CONFIG_VALUE_0", "me@example.com")
+
+	c := New().(*coAuthors)
+	c.SetConfig(gc.ModuleConfig{Name: MODULE_NAME, Active: true, Options: json.RawMessage(`{"coauthors": [
+		{"id": "me@example.com", "name": "Me", "emoji": "🦊"},
+		{"id": "bob@example.com", "name": "Bob", "emoji": "🐝"}
+	]}`)})
+	if err := c.LoadConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	commit, err := gc.Parse("feat: add thing\n\nCo-authored-by: Alice <alice@x>\nCo-authored-by: Bob <bob@example.com>\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.InitCommitInfo(&commit); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Alice <alice@x>", "bob@example.com"}; !slices.Equal(commit.CoAuthoredBy, want) {
+		t.Errorf("CoAuthoredBy = %q, want %q", commit.CoAuthoredBy, want)
+	}
+
+	if err := c.PostProcess(&commit); err != nil {
+		t.Fatal(err)
+	}
+	want := []gc.Trailer{
+		{Key: "Co-authored-by", Value: "Alice <alice@x>"},
+		{Key: "Co-authored-by", Value: "Bob <bob@example.com>"},
+	}
+	if !reflect.DeepEqual(commit.Trailers, want) {
+		t.Errorf("Trailers = %q, want %q", commit.Trailers, want)
+	}
+	if got := commit.Extra("signature"); got != "🦊 🐝" {
+		t.Errorf("signature = %q, want %q", got, "🦊 🐝")
+	}
+}
@@ -1,31 +1,296 @@
 package goodcommit
 
 import (
+	"bytes"
+	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
 )
 
-type config struct {
+// Config is the effective configuration, merged from all the configuration layers.
+type Config struct {
 	ModulesToActivate []ModuleConfig `json:"activeModules"`
+	// Template is the text/template the commit message is rendered with, see Template. Instead
+	// of writing it in the configuration, TemplateFile can point to a file with it. When neither
+	// is set, DefaultTemplateText is used.
+	Template     string `json:"template,omitempty"`
+	TemplateFile string `json:"templateFile,omitempty"`
+	// Origins holds, for each module name and configuration field, the layer the value came from.
+	Origins map[string]map[string]ConfigLayer `json:"-"`
 }
 
-// LoadConfigToModules loads the configuration from the config file into the
-// modules.
-func LoadConfigToModules(modules []Module, configPath string) ([]Module, error) {
-	var cfg config
+// ConfigLayer is a configuration file merged into the effective configuration.
+type ConfigLayer struct {
+	Name string // One of system, user, repo, local or cli.
+	Path string
+}
+
+// defaultConfig is the configuration used when no configuration file is found: the Conventional
+// Commits types, a description, a body and the breaking changes.
+//
+//go:embed configs/default.json
+var defaultConfig []byte
+
+// DefaultConfig returns the content of the built-in configuration, in JSON.
+func DefaultConfig() []byte {
+	return append([]byte(nil), defaultConfig...)
+}
+
+// BuiltinLayer is the layer of the built-in configuration, see DefaultConfig.
+var BuiltinLayer = ConfigLayer{Name: "builtin", Path: "built-in default configuration"}
+
+// Names of the files searched for in the repository. Besides .json, the .yaml, .yml and .toml
+// extensions are also looked for, see ConfigExtensions.
+const (
+	RepoConfigName  = ".goodcommit.json"
+	LocalConfigName = ".goodcommit.local.json"
+)
+
+// AllowRepoExec lets the repo and local configuration files set the exec command of modules.
+// Those files come with the repository, so by default only the system, user and cli layers can
+// run commands: a pull request could otherwise run its own code when goodcommit is used on it.
+// The command line sets it with --allow-exec or GOODCOMMIT_ALLOW_EXEC.
+var AllowRepoExec bool
+
+// trustedExecLayers are the layers that can set the exec command of modules without AllowRepoExec.
+var trustedExecLayers = []string{"system", "user", "cli"}
+
+// DiscoverConfig returns the configuration files that exist, from lowest to highest precedence:
+//
+//  1. system: /etc/goodcommit/config.json, e.g. a company-wide default.
+//  2. user: goodcommit/config.json in the user config dir (~/.config/goodcommit/config.json on Linux).
+//  3. repo: .goodcommit.json, found walking up from the working directory to the git root.
+//  4. local: .goodcommit.local.json next to the repo file, for per-user overrides that are not committed.
+//  5. cli: the path given with --config or GOODCOMMIT_CONFIG_PATH.
+//
+// The first file found with any of the ConfigExtensions is used for each layer, e.g. .goodcommit.yaml.
+// When there are none, the BuiltinLayer is returned so goodcommit works without configuration.
+func DiscoverConfig(cliPath string) []ConfigLayer {
+	var layers []ConfigLayer
+	add := func(name, path string) {
+		if path = findConfigFile(path); path != "" {
+			layers = append(layers, ConfigLayer{Name: name, Path: path})
+		}
+	}
+
+	add("system", filepath.Join("/etc", "goodcommit", "config.json"))
+	if dir, err := os.UserConfigDir(); err == nil {
+		add("user", filepath.Join(dir, "goodcommit", "config.json"))
+	}
+	if dir := findRepoConfigDir(); dir != "" {
+		add("repo", filepath.Join(dir, RepoConfigName))
+		add("local", filepath.Join(dir, LocalConfigName))
+	}
+	if cliPath != "" {
+		// The cli layer is always added, so a missing file is reported when loading
+		layers = append(layers, ConfigLayer{Name: "cli", Path: cliPath})
+	}
+	if len(layers) == 0 {
+		layers = append(layers, BuiltinLayer)
+	}
+	return layers
+}
+
+// findRepoConfigDir walks up from the working directory to the git root, returning the first
+// directory with a repo or local configuration file.
+func findRepoConfigDir() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	root := dir
+	if out, err := exec.Command("git", "rev-parse", "--show-toplevel").Output(); err == nil {
+		root = strings.TrimSpace(string(out))
+	}
+
+	for {
+		for _, name := range []string{RepoConfigName, LocalConfigName} {
+			if findConfigFile(filepath.Join(dir, name)) != "" {
+				return dir
+			}
+		}
+		parent := filepath.Dir(dir)
+		if dir == root || parent == dir || !strings.HasPrefix(dir, root) {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// LoadConfig merges the given layers into the effective configuration. Modules are merged by
+// name, field by field, so a layer only needs to set the fields it overrides. Relative paths in
+// discovered layers are resolved from the directory of the file that sets them, paths in the
+// cli layer are kept relative to the working directory.
+// The problems found in every layer are returned together.
+func LoadConfig(layers []ConfigLayer) (Config, error) {
+	cfg := Config{Origins: make(map[string]map[string]ConfigLayer)}
+	if len(layers) == 0 {
+		return cfg, fmt.Errorf("no configuration layers to load")
+	}
+
+	var names []string
+	var errs []error
+	fields := make(map[string]map[string]json.RawMessage)
+
+	for _, layer := range layers {
+		content, err := defaultConfig, error(nil)
+		if layer != BuiltinLayer {
+			content, err = readConfigFile(layer.Path)
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		// Decode the whole layer first to report unknown keys and wrong types with their position
+		if err := decodeConfig(layer.Path, content, &Config{}); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		var raw struct {
+			ModulesToActivate []map[string]json.RawMessage `json:"activeModules"`
+			Template          *string                      `json:"template"`
+			TemplateFile      json.RawMessage              `json:"templateFile"`
+		}
+		content, _ = toJSON(layer.Path, content)
+		json.Unmarshal(content, &raw)
+
+		// A layer with a template replaces the template of the layers below, whichever way it is given
+		if raw.Template != nil || raw.TemplateFile != nil {
+			cfg.Template, cfg.TemplateFile = "", ""
+			if raw.Template != nil {
+				cfg.Template = *raw.Template
+			}
+			if raw.TemplateFile != nil {
+				if layer.Name != "cli" {
+					raw.TemplateFile = resolvePath(raw.TemplateFile, filepath.Dir(layer.Path))
+				}
+				json.Unmarshal(raw.TemplateFile, &cfg.TemplateFile)
+			}
+		}
+
+		for i, entry := range raw.ModulesToActivate {
+			var name string
+			if err := json.Unmarshal(entry["name"], &name); err != nil || name == "" {
+				errs = append(errs, &ConfigParseError{Path: layer.Path, Err: fmt.Errorf("activeModules[%d] has no name", i)})
+				continue
+			}
+			if fields[name] == nil {
+				names = append(names, name)
+				fields[name] = make(map[string]json.RawMessage)
+				cfg.Origins[name] = make(map[string]ConfigLayer)
+			}
+			for key, value := range entry {
+				if (key == "path" || key == "exec" && bytes.ContainsRune(value, '/')) && layer.Name != "cli" {
+					// Commands without a directory, e.g. "jira-module", are looked up in the PATH
+					value = resolvePath(value, filepath.Dir(layer.Path))
+				}
+				fields[name][key] = value
+				cfg.Origins[name][key] = layer
+			}
+		}
+	}
+
+	for _, name := range names {
+		merged, _ := json.Marshal(fields[name])
+		mc := ModuleConfig{}
+		if err := json.Unmarshal(merged, &mc); err != nil {
+			errs = append(errs, &ModuleError{Module: name, Err: err})
+			continue
+		}
+		cfg.ModulesToActivate = append(cfg.ModulesToActivate, mc)
+	}
+	return cfg, errors.Join(errs...)
+}
+
+// findConfigFile returns the first file that exists at path with any of the ConfigExtensions
+// instead of its own, or "" if there is none.
+func findConfigFile(path string) string {
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	for _, ext := range ConfigExtensions {
+		if info, err := os.Stat(base + ext); err == nil && !info.IsDir() {
+			return base + ext
+		}
+	}
+	return ""
+}
+
+// resolvePath makes a relative JSON string path absolute from dir.
+func resolvePath(value json.RawMessage, dir string) json.RawMessage {
+	var path string
+	if err := json.Unmarshal(value, &path); err != nil || path == "" || filepath.IsAbs(path) {
+		return value
+	}
+	resolved, _ := json.Marshal(filepath.Join(dir, path))
+	return resolved
+}
+
+// LoadModules creates the modules of the configuration from the registry and loads their
+// configuration, see LoadConfigToModules.
+func LoadModules(configPath string) ([]Module, error) {
+	return LoadConfigToModules(nil, configPath)
+}
+
+// LoadTemplate returns the message template of the configuration, merged like in LoadModules.
+func LoadTemplate(configPath string) (*Template, error) {
+	cfg, err := LoadConfig(DiscoverConfig(configPath))
+	if err != nil {
+		return nil, err
+	}
+	return cfg.MessageTemplate()
+}
 
-	raw, err := os.ReadFile(configPath)
+// MessageTemplate returns the message template of the configuration, DefaultTemplate if it has none.
+func (cfg Config) MessageTemplate() (*Template, error) {
+	switch {
+	case cfg.Template != "" && cfg.TemplateFile != "":
+		return nil, errors.New("template and templateFile cannot be used together")
+	case cfg.Template != "":
+		return ParseTemplate(cfg.Template)
+	case cfg.TemplateFile != "":
+		return ReadTemplate(cfg.TemplateFile)
+	}
+	return DefaultTemplate(), nil
+}
+
+// LoadConfigToModules loads the configuration into the modules. The configuration is merged from
+// the files found by DiscoverConfig, with the file at configPath (if any) taking precedence.
+// The returned modules are the ones in the configuration, in its order: the given module with
+// the same name if any, or else a new instance created with NewModule.
+// Instead of stopping at the first problem, the errors of every module are returned together,
+// e.g. an *UnknownModuleError for each module in the configuration that does not exist, or a
+// *ModuleError for an exec command that is not trusted, see AllowRepoExec.
+func LoadConfigToModules(modules []Module, configPath string) ([]Module, error) {
+	cfg, err := LoadConfig(DiscoverConfig(configPath))
 	if err != nil {
-		fmt.Println("Error occurred while reading config:", err)
-		os.Exit(1)
+		return nil, err
 	}
+	return cfg.loadModules(modules, true)
+}
 
-	// Load ModulesToActivate from config
-	err = json.Unmarshal(raw, &cfg)
+// LoadValidators creates the modules of the configuration like LoadModules, but without starting
+// the external ones, to check messages without running any command of the configuration, e.g.
+// in the lint command. External modules are returned unloaded, as they don't validate messages.
+func LoadValidators(configPath string) ([]Module, error) {
+	cfg, err := LoadConfig(DiscoverConfig(configPath))
 	if err != nil {
-		fmt.Println("Error occurred while parsing config:", err)
-		os.Exit(1)
+		return nil, err
+	}
+	return cfg.loadModules(nil, false)
+}
+
+// loadModules creates and loads the modules of the configuration, see LoadConfigToModules.
+// External modules are only loaded, which starts their command, when external is set.
+func (cfg Config) loadModules(modules []Module, external bool) ([]Module, error) {
+	var errs []error
+	given := make(map[string]Module)
+	for _, m := range modules {
+		given[m.Name()] = m
 	}
 
 	activeModules := make(map[string]bool)
@@ -37,30 +302,202 @@ func LoadConfigToModules(modules []Module, configPath string) ([]Module, error)
 		}
 	}
 
-	// Second pass: Filter modules based on dependencies being met
+	// Second pass: Create the modules and load the active ones, if their dependencies are met
+	var loaded []Module
 	for _, mc := range cfg.ModulesToActivate {
-		for _, m := range modules {
-			if m.Name() == mc.Name && mc.Active { // Ensure module is active before checking dependencies
-				// Check if all dependencies are met
-				allDependenciesMet := true
-				for _, dep := range mc.Dependencies {
-					if !activeModules[dep] {
-						allDependenciesMet = false
-						break
-					}
-				}
+		m := given[mc.Name]
+		if m == nil {
+			var err error
+			if m, err = NewModule(mc); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+		loaded = append(loaded, m)
+		if !mc.Active || mc.Exec != "" && !external {
+			m.SetConfig(mc)
+			continue
+		}
+		if mc.Exec != "" {
+			if err := cfg.checkExec(mc); err != nil {
+				errs = append(errs, &ModuleError{Module: mc.Name, Err: err})
+				continue
+			}
+		}
 
-				// If all dependencies are met, set config and load it
-				if allDependenciesMet {
-					m.SetConfig(mc)
-					if m.IsActive() {
-						m.LoadConfig()
-					}
-				} else {
-					return nil, fmt.Errorf("module %s has unmet dependencies", mc.Name)
+		// Check if all dependencies are met
+		allDependenciesMet := true
+		for _, dep := range mc.Dependencies {
+			if !activeModules[dep] {
+				allDependenciesMet = false
+				break
+			}
+		}
+
+		// The when expression is checked now, so a typo is not found halfway through the form
+		if mc.When != "" {
+			if _, err := ParseCondition(mc.When); err != nil {
+				errs = append(errs, &ModuleError{Module: mc.Name, Err: err})
+			}
+		}
+
+		// If all dependencies are met, set config and load it
+		if allDependenciesMet {
+			m.SetConfig(mc)
+			if err := m.LoadConfig(); err != nil {
+				errs = append(errs, &ModuleError{Module: mc.Name, Err: err})
+			}
+		} else {
+			errs = append(errs, &ModuleError{Module: mc.Name, Err: fmt.Errorf("unmet dependencies %v", mc.Dependencies)})
+		}
+	}
+	if len(errs) > 0 {
+		// The external modules that were started are not needed anymore
+		CloseModules(loaded)
+		return nil, errors.Join(errs...)
+	}
+	BindModules(loaded)
+	return loaded, nil
+}
+
+// checkExec returns an error if the exec command of the module was set by a layer that is not
+// trusted to run commands, see AllowRepoExec.
+func (cfg Config) checkExec(mc ModuleConfig) error {
+	layer := cfg.Origins[mc.Name]["exec"]
+	if AllowRepoExec || slices.Contains(trustedExecLayers, layer.Name) {
+		return nil
+	}
+	return fmt.Errorf("exec command %q of %s is not trusted, only the system, user and --config files can set it unless --allow-exec is given", mc.Exec, layer.Path)
+}
+
+// ConfigChecker is implemented by modules that can check their configuration against the other
+// loaded modules, e.g. that the scopes are only conditional on existing types.
+type ConfigChecker interface {
+	CheckConfig(modules []Module) []error
+}
+
+// ValidateConfig checks the configuration for problems that decoding it doesn't catch: unknown
+// modules, two active modules in the same position of a page, dependencies on unknown or inactive
+// modules, dependency cycles, invalid when expressions and priorities out of the 0-99 range the
+// commiter goes through. It then creates the modules, loads the configuration files of the active ones and runs their
+// ConfigChecker. External modules are created but never started. All the problems found are returned together.
+func ValidateConfig(cfg Config) error {
+	var errs []error
+	var modules []Module
+	known := make(map[string]Module)
+	configs := make(map[string]ModuleConfig)
+	for _, mc := range cfg.ModulesToActivate {
+		configs[mc.Name] = mc
+		m, err := NewModule(mc)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		modules = append(modules, m)
+		known[mc.Name] = m
+	}
+
+	positions := make(map[[3]int]string)
+	for _, mc := range cfg.ModulesToActivate {
+		if known[mc.Name] == nil {
+			continue
+		}
+		if !mc.Active {
+			continue
+		}
+		if mc.Priority < 0 || mc.Priority > 99 {
+			errs = append(errs, &ModuleError{Module: mc.Name, Err: fmt.Errorf("priority %d is out of the 0-99 range", mc.Priority)})
+		}
+		// Pinned modules are placed before the rest, so they only collide with each other
+		key := [3]int{mc.Page, mc.Position, 0}
+		if mc.Pinned {
+			key[2] = 1
+		}
+		if other, ok := positions[key]; ok {
+			errs = append(errs, &ModuleError{Module: mc.Name, Err: fmt.Errorf("position %d of page %d is already used by module %s", mc.Position, mc.Page, other)})
+		} else {
+			positions[key] = mc.Name
+		}
+		if mc.When != "" {
+			if _, err := ParseCondition(mc.When); err != nil {
+				errs = append(errs, &ModuleError{Module: mc.Name, Err: err})
+			}
+		}
+		for _, dep := range mc.Dependencies {
+			if _, ok := configs[dep]; !ok && registry[dep] == nil {
+				errs = append(errs, &ModuleError{Module: mc.Name, Err: fmt.Errorf("depends on unknown module %s", dep)})
+			} else if !configs[dep].Active {
+				errs = append(errs, &ModuleError{Module: mc.Name, Err: fmt.Errorf("depends on inactive module %s", dep)})
+			}
+		}
+	}
+	errs = append(errs, dependencyCycles(cfg)...)
+
+	// Load the files of the modules before checking them against each other. External modules
+	// are not started, validating a configuration must not run the commands in it
+	loaded := true
+	for _, mc := range cfg.ModulesToActivate {
+		if m := known[mc.Name]; m != nil && mc.Active && mc.Exec == "" {
+			m.SetConfig(mc)
+			if err := m.LoadConfig(); err != nil {
+				errs = append(errs, &ModuleError{Module: mc.Name, Err: err})
+				loaded = false
+			}
+		}
+	}
+	for _, m := range modules {
+		// Checking against modules that failed to load would only report their missing values
+		if c, ok := m.(ConfigChecker); ok && m.IsActive() && loaded {
+			for _, err := range c.CheckConfig(modules) {
+				errs = append(errs, &ModuleError{Module: m.Name(), Err: err})
+			}
+		}
+	}
+
+	// Rendering a blank commit catches the fields that don't exist, e.g. {{.Scopez}}
+	if tmpl, err := cfg.MessageTemplate(); err != nil {
+		errs = append(errs, err)
+	} else if _, err := tmpl.Render(Commit{Extras: make(map[string]*string)}); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// dependencyCycles returns an error for every cycle in the dependencies of the modules.
+func dependencyCycles(cfg Config) []error {
+	deps := make(map[string][]string)
+	for _, mc := range cfg.ModulesToActivate {
+		deps[mc.Name] = mc.Dependencies
+	}
+
+	var errs []error
+	const visiting, done = 1, 2
+	state := make(map[string]int)
+	var path []string
+	var visit func(name string)
+	visit = func(name string) {
+		switch state[name] {
+		case visiting:
+			for i, n := range path {
+				if n == name {
+					cycle := append(append([]string{}, path[i:]...), name)
+					errs = append(errs, &ModuleError{Module: name, Err: fmt.Errorf("dependency cycle %s", strings.Join(cycle, " -> "))})
 				}
 			}
+			return
+		case done:
+			return
+		}
+		state[name] = visiting
+		path = append(path, name)
+		for _, dep := range deps[name] {
+			visit(dep)
 		}
+		path = path[:len(path)-1]
+		state[name] = done
+	}
+	for _, mc := range cfg.ModulesToActivate {
+		visit(mc.Name)
 	}
-	return modules, nil
+	return errs
 }
@@ -0,0 +1,150 @@
+package goodcommit_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	gc "github.com/nantli/goodcommit"
+	_ "github.com/nantli/goodcommit/body"
+	_ "github.com/nantli/goodcommit/breaking"
+	_ "github.com/nantli/goodcommit/breakingmsg"
+	_ "github.com/nantli/goodcommit/coauthors"
+	_ "github.com/nantli/goodcommit/description"
+	_ "github.com/nantli/goodcommit/greetings"
+	_ "github.com/nantli/goodcommit/logo"
+	_ "github.com/nantli/goodcommit/plugin"
+	_ "github.com/nantli/goodcommit/scopes"
+	_ "github.com/nantli/goodcommit/signedoffby"
+	_ "github.com/nantli/goodcommit/types"
+	_ "github.com/nantli/goodcommit/why"
+)
+
+// TestExampleConfigFormats loads the example configuration in every format, and checks that
+// the YAML and TOML ones, and the module files they point to, give the same modules as the JSON one.
+func TestExampleConfigFormats(t *testing.T) {
+	// Keep the configuration of the user out of the discovered layers
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	load := func(path string) ([]gc.ModuleConfig, []gc.Module) {
+		t.Helper()
+		cfg, err := gc.LoadConfig([]gc.ConfigLayer{{Name: "cli", Path: path}})
+		if err != nil {
+			t.Fatalf("LoadConfig(%s): %v", path, err)
+		}
+		modules, err := gc.LoadConfigToModules(nil, path)
+		if err != nil {
+			t.Fatalf("LoadConfigToModules(%s): %v", path, err)
+		}
+		return cfg.ModulesToActivate, modules
+	}
+
+	wantConfigs, wantModules := load("configs/config.example.json")
+	if len(wantModules) == 0 {
+		t.Fatal("configs/config.example.json has no modules")
+	}
+
+	for _, path := range []string{"configs/config.example.yaml", "configs/config.example.toml"} {
+		t.Run(path, func(t *testing.T) {
+			configs, modules := load(path)
+			if len(configs) != len(wantConfigs) || len(modules) != len(wantModules) {
+				t.Fatalf("got %d modules, want %d", len(modules), len(wantModules))
+			}
+			for i, want := range wantConfigs {
+				// The module files are in other formats too, the items are compared below
+				got := configs[i]
+				got.Path, want.Path = "", ""
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("module %d config = %+v, want %+v", i, got, want)
+				}
+
+				// The items of the modules only differ in the file they were read from
+				m := modules[i]
+				m.SetConfig(wantModules[i].Config())
+				if !reflect.DeepEqual(m, wantModules[i]) {
+					t.Errorf("module %s = %+v, want %+v", want.Name, m, wantModules[i])
+				}
+			}
+		})
+	}
+}
+
+// TestCommittedConfigsValid checks that every configuration committed in the repository passes
+// "goodcommit config validate", the built-in default included.
+func TestCommittedConfigsValid(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	paths, err := filepath.Glob("configs/config.*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths = append(paths, "configs/default.json", ".repo/configs/config.json")
+	for _, path := range paths {
+		t.Run(path, func(t *testing.T) {
+			cfg, err := gc.LoadConfig([]gc.ConfigLayer{{Name: "cli", Path: path}})
+			if err != nil {
+				t.Fatalf("LoadConfig: %v", err)
+			}
+			if err := gc.ValidateConfig(cfg); err != nil {
+				t.Errorf("ValidateConfig: %v", err)
+			}
+		})
+	}
+}
+
+// TestRegisteredModuleNames checks that the built-in modules are created with the name they are
+// registered under.
+func TestRegisteredModuleNames(t *testing.T) {
+	for _, name := range gc.Registered() {
+		if got := gc.NewRegistered(name).Name(); got != name {
+			t.Errorf("module registered as %s is named %q", name, got)
+		}
+	}
+}
+
+// TestRepoExecNotTrusted checks that the exec command of a repository file is not run without
+// AllowRepoExec, while the same command given with --config is.
+func TestRepoExecNotTrusted(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	config := `{"activeModules": [{"name": "evil", "active": true, "exec": "goodcommit-missing-module"}]}`
+	if err := os.WriteFile(filepath.Join(dir, gc.RepoConfigName), []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	// The command does not exist, so starting it fails instead of running anything
+	tests := []struct {
+		name       string
+		configPath string
+		allow      bool
+		want       string
+	}{
+		{"repo", "", false, "not trusted"},
+		{"repo allowed", "", true, "error starting"},
+		{"cli", gc.RepoConfigName, false, "error starting"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gc.AllowRepoExec = tt.allow
+			defer func() { gc.AllowRepoExec = false }()
+
+			_, err := gc.LoadModules(tt.configPath)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("LoadModules() error = %v, want it to contain %q", err, tt.want)
+			}
+			if _, err := gc.LoadValidators(tt.configPath); err != nil {
+				t.Errorf("LoadValidators() error = %v, external modules must not be started", err)
+			}
+		})
+	}
+}
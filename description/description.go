@@ -3,7 +3,9 @@
 package description
 
 import (
+	"fmt"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/huh"
 	gc "github.com/nantli/goodcommit"
@@ -11,23 +13,59 @@ import (
 
 const MODULE_NAME = "description"
 
+// DEFAULT_MAX_LENGTH is the maximum length of the description when the options don't set one.
+const DEFAULT_MAX_LENGTH = 50
+
 type description struct {
-	config gc.ModuleConfig
+	config    gc.ModuleConfig
+	MaxLength int `json:"maxLength"`
 }
 
+// LoadConfig loads the module options.
+// Example options:
+//
+//	"options": {
+//	    "maxLength": 72
+//	}
 func (d *description) LoadConfig() error {
-	return nil
+	d.MaxLength = DEFAULT_MAX_LENGTH
+	return d.config.DecodeOptions(d)
 }
 
-// NewField returns a new Input field for the user to write a brief description of the commit (max 50 chars).
+// NewField returns a new Input field for the user to write a brief description of the commit (max 50 chars by default).
 func (d *description) NewField(commit *gc.Commit) (huh.Field, error) {
 	return huh.NewInput().
 		Title("✏️・Write the Commit Description").
-		Description("Briefly describe the changes in this commit (max 50 chars).").
-		CharLimit(50).
+		Description(fmt.Sprintf("Briefly describe the changes in this commit (max %d chars).", d.MaxLength)).
+		CharLimit(d.MaxLength).
 		Value(&commit.Description), nil
 }
 
+// Inputs lets the description be given with --description when running without a form.
+func (d *description) Inputs() []gc.Input {
+	return []gc.Input{{
+		Key:      "description",
+		Short:    "d",
+		Usage:    "Brief description of the commit",
+		Required: true,
+		Set: func(commit *gc.Commit, values []string) error {
+			if utf8.RuneCountInString(values[0]) > d.MaxLength {
+				return fmt.Errorf("description is longer than %d chars", d.MaxLength)
+			}
+			commit.Description = values[0]
+			return nil
+		},
+	}}
+}
+
+// Validate checks that the description is not longer than the maximum length.
+func (d *description) Validate(commit *gc.Commit) []gc.Violation {
+	if n := utf8.RuneCountInString(commit.Description); n > d.MaxLength {
+		return []gc.Violation{{Field: "description", Message: fmt.Sprintf("description is %d chars long, max is %d", n, d.MaxLength)}}
+	}
+	return nil
+}
+
 // PostProcess lowercases the first letter of the commit description.
 func (d *description) PostProcess(commit *gc.Commit) error {
 	if commit.Description == "" {
@@ -47,7 +85,7 @@ func (d *description) SetConfig(config gc.ModuleConfig) {
 }
 
 func (d *description) Name() string {
-	return MODULE_NAME
+	return d.config.Name
 }
 
 func (d *description) InitCommitInfo(commit *gc.Commit) error {
@@ -59,5 +97,9 @@ func (d *description) IsActive() bool {
 }
 
 func New() gc.Module {
-	return &description{config: gc.ModuleConfig{Name: MODULE_NAME}}
+	return &description{config: gc.ModuleConfig{Name: MODULE_NAME}, MaxLength: DEFAULT_MAX_LENGTH}
+}
+
+func init() {
+	gc.Register(MODULE_NAME, New)
 }
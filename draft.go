@@ -0,0 +1,90 @@
+package goodcommit
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Draft is a commit being filled in the form. It is saved in the git directory of the repository
+// as the form is filled, so it can be resumed after the form is interrupted, e.g. with Ctrl+C or
+// by an error of a module.
+type Draft struct {
+	ID      string    `json:"id"`
+	Branch  string    `json:"branch,omitempty"`
+	Updated time.Time `json:"updated"`
+	Commit  Commit    `json:"commit"` // The values of the form, before post-processing.
+}
+
+// NewDraft returns a new draft for the current branch, it is not saved until Save is called.
+func NewDraft() *Draft {
+	now := time.Now()
+	return &Draft{ID: newEntryID("drafts", now), Branch: CurrentBranch(), Updated: now}
+}
+
+// Summary returns the title of the commit of the draft, e.g. "feat: add parser".
+func (d *Draft) Summary() string {
+	summary := d.Commit.Type
+	if d.Commit.Description != "" {
+		summary += ": " + d.Commit.Description
+	}
+	if summary == "" {
+		return "(empty)"
+	}
+	return summary
+}
+
+// Save stores the commit in the draft file, in .git/goodcommit/drafts.
+func (d *Draft) Save(commit Commit) error {
+	dir, err := DraftsDir()
+	if err != nil {
+		return err
+	}
+	d.Commit = commit.Clone()
+	d.Updated = time.Now()
+	return writeEntry(dir, d.ID, d)
+}
+
+// Drop removes the draft file, if it was saved.
+func (d *Draft) Drop() error {
+	dir, err := DraftsDir()
+	if err != nil {
+		return err
+	}
+	return removeEntry(dir, d.ID)
+}
+
+// DraftsDir returns the directory where the drafts of the repository are saved.
+func DraftsDir() (string, error) {
+	return dataDir("drafts")
+}
+
+// Drafts returns the saved drafts of the repository, the most recently updated first.
+func Drafts() ([]*Draft, error) {
+	dir, err := DraftsDir()
+	if err != nil {
+		return nil, err
+	}
+	drafts, err := readEntries[Draft](dir)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(drafts, func(i, j int) bool {
+		return drafts[i].Updated.After(drafts[j].Updated)
+	})
+	return drafts, nil
+}
+
+// LoadDraft returns the saved draft with the given id.
+func LoadDraft(id string) (*Draft, error) {
+	drafts, err := Drafts()
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range drafts {
+		if d.ID == id {
+			return d, nil
+		}
+	}
+	return nil, fmt.Errorf("no draft %q", id)
+}
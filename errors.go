@@ -0,0 +1,139 @@
+package goodcommit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ConfigNotFoundError is returned when a configuration file does not exist.
+type ConfigNotFoundError struct {
+	Path string
+	Err  error
+}
+
+func (e *ConfigNotFoundError) Error() string {
+	return fmt.Sprintf("config file %s not found", e.Path)
+}
+
+func (e *ConfigNotFoundError) Unwrap() error {
+	return e.Err
+}
+
+// ConfigParseError is returned when a configuration file can't be parsed.
+// Line and Column point to the position of the problem, starting at 1, and are 0 if unknown.
+type ConfigParseError struct {
+	Path   string
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *ConfigParseError) Error() string {
+	if e.Line == 0 {
+		return fmt.Sprintf("error parsing %s: %v", e.Path, e.Err)
+	}
+	return fmt.Sprintf("error parsing %s:%d:%d: %v", e.Path, e.Line, e.Column, e.Err)
+}
+
+func (e *ConfigParseError) Unwrap() error {
+	return e.Err
+}
+
+// UnknownModuleError is returned when the configuration references a module that does not exist.
+type UnknownModuleError struct {
+	Name string
+}
+
+func (e *UnknownModuleError) Error() string {
+	return fmt.Sprintf("unknown module %q", e.Name)
+}
+
+// ModuleError wraps an error returned by a module, e.g. while loading its configuration.
+type ModuleError struct {
+	Module string
+	Err    error
+}
+
+func (e *ModuleError) Error() string {
+	return fmt.Sprintf("module %s: %v", e.Module, e.Err)
+}
+
+func (e *ModuleError) Unwrap() error {
+	return e.Err
+}
+
+// ReadConfigFile reads the configuration file at path and decodes it into v.
+// It returns a *ConfigNotFoundError if the file does not exist and a *ConfigParseError,
+// with the line and column of the problem, if it can't be decoded.
+func ReadConfigFile(path string, v any) error {
+	raw, err := readConfigFile(path)
+	if err != nil {
+		return err
+	}
+	return decodeConfig(path, raw, v)
+}
+
+// readConfigFile returns the content of the configuration file at path.
+func readConfigFile(path string) ([]byte, error) {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, &ConfigNotFoundError{Path: path, Err: err}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", path, err)
+	}
+	return raw, nil
+}
+
+// decodeConfig decodes the content of the configuration file at path into v, as JSON, YAML or
+// TOML depending on its extension.
+// Decoding is strict, keys that don't match a field of v are reported instead of ignored,
+// so typos like "dependancies" don't go unnoticed.
+func decodeConfig(path string, raw []byte, v any) error {
+	content, err := toJSON(path, raw)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(content))
+	dec.DisallowUnknownFields()
+	err = dec.Decode(v)
+	if err == nil {
+		return nil
+	}
+
+	// Offsets are only meaningful when the file is JSON, in other formats they point to the converted content
+	parseErr := &ConfigParseError{Path: path, Err: err}
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr) && isJSON(path):
+		parseErr.Line, parseErr.Column = position(raw, syntaxErr.Offset-1)
+	case errors.As(err, &typeErr) && isJSON(path):
+		parseErr.Line, parseErr.Column = position(raw, typeErr.Offset-1)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// The decoder doesn't report where the field is, point to the first use of the key
+		key := strings.TrimPrefix(err.Error(), "json: unknown field ")
+		if !isJSON(path) {
+			key, _ = strconv.Unquote(key)
+		}
+		if i := bytes.Index(raw, []byte(key)); i >= 0 {
+			parseErr.Line, parseErr.Column = position(raw, int64(i))
+		}
+	}
+	return parseErr
+}
+
+// position returns the line and column of the byte at offset in raw.
+func position(raw []byte, offset int64) (int, int) {
+	offset = max(0, min(offset, int64(len(raw))))
+	before := raw[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
@@ -0,0 +1,82 @@
+#!/usr/bin/env python3
+"""Reference external module for goodcommit, see the plugin package.
+
+It asks for the ticket the commit refers to and adds it as a "Refs:" trailer.
+
+    {
+        "name": "ticket",
+        "active": true,
+        "page": 3,
+        "position": 1,
+        "exec": "./examples/plugins/ticket.py",
+        "options": { "project": "GC" }
+    }
+
+Run it locally with:
+
+    goodcommit plugin test --options '{"project": "GC"}' --set ticket=42 ./examples/plugins/ticket.py
+"""
+
+import json
+import re
+import sys
+
+project = ""
+
+
+def load_config(config):
+    global project
+    project = (config.get("options") or {}).get("project", "")
+    return {}
+
+
+def init_commit_info(commit):
+    # When amending, move the trailer back to the field
+    footer = commit.get("footer") or ""
+    match = re.search(r"\nRefs: (\S+)", footer)
+    if match:
+        commit["extras"]["ticket"] = match.group(1)
+        commit["footer"] = footer.replace(match.group(0), "", 1)
+    return {"commit": commit}
+
+
+def new_field(commit):
+    example = f"{project}-123" if project else "123"
+    return {
+        "field": {
+            "kind": "input",
+            "key": "ticket",
+            "title": "🎫・Ticket",
+            "description": "The ticket this commit refers to, leave empty if none.",
+            "placeholder": example,
+            "charLimit": 20,
+        }
+    }
+
+
+def post_process(commit):
+    ticket = (commit["extras"].get("ticket") or "").strip()
+    if not ticket:
+        return {"commit": commit}
+    if ticket.isdigit() and project:
+        ticket = f"{project}-{ticket}"
+    if not re.fullmatch(r"[A-Za-z][A-Za-z0-9]*-\d+|\d+", ticket):
+        return {"error": f"invalid ticket {ticket!r}"}
+    commit["footer"] = (commit.get("footer") or "") + f"\nRefs: {ticket.upper()}"
+    return {"commit": commit}
+
+
+handlers = {
+    "loadConfig": lambda req: load_config(req["config"]),
+    "initCommitInfo": lambda req: init_commit_info(req["commit"]),
+    "newField": lambda req: new_field(req["commit"]),
+    "postProcess": lambda req: post_process(req["commit"]),
+}
+
+for line in sys.stdin:
+    request = json.loads(line)
+    if request.get("commit") is not None and request["commit"].get("extras") is None:
+        request["commit"]["extras"] = {}
+    handler = handlers.get(request["method"])
+    response = handler(request) if handler else {"error": f"unknown method {request['method']}"}
+    print(json.dumps(response), flush=True)
@@ -0,0 +1,45 @@
+package goodcommit
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// CommitExecutor creates the commit with the rendered message.
+// The default implementation runs git, tests and custom tools can provide their own.
+type CommitExecutor interface {
+	// Commit commits the staged changes with the given message and returns the output of the command.
+	Commit(message string) ([]byte, error)
+}
+
+type gitExecutor struct {
+	args []string
+}
+
+// NewGitExecutor returns a CommitExecutor that runs "git commit -F" with the message written
+// to a temporary file. Git is called with an argument list, not through a shell, so messages
+// with backticks, "$()" or backslashes are committed as written.
+// The given args are passed through to git commit, e.g. "--amend", "--no-verify", "-S" or "--allow-empty".
+func NewGitExecutor(args ...string) CommitExecutor {
+	return &gitExecutor{args: args}
+}
+
+func (e *gitExecutor) Commit(message string) ([]byte, error) {
+	f, err := os.CreateTemp("", "goodcommit-msg-*.txt")
+	if err != nil {
+		return nil, fmt.Errorf("error creating message file: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.WriteString(message); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("error writing message file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return nil, fmt.Errorf("error writing message file: %w", err)
+	}
+
+	args := append([]string{"commit", "-F", f.Name()}, e.args...)
+	return exec.Command("git", args...).CombinedOutput()
+}
@@ -0,0 +1,45 @@
+package goodcommit
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// TestGitExecutor checks that messages are committed as written, and that the arguments are
+// passed to git commit.
+func TestGitExecutor(t *testing.T) {
+	dir := t.TempDir()
+	if out, err := exec.Command("git", "init", "-q", dir).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v: %s", err, out)
+	}
+	t.Setenv("GIT_CONFIG_COUNT", "3")
+	t.Setenv("GIT_CONFIG_KEY_0", "user.name")
+	t.Setenv("GIT_CONFIG_VALUE_0", "Me")
+	t.Setenv("GIT_CONFIG_KEY_1", "user.email")
+	t.Setenv("GIT_CONFIG_VALUE_1", "me@example.com")
+	t.Setenv("GIT_CONFIG_KEY_2", "commit.gpgsign")
+	t.Setenv("GIT_CONFIG_VALUE_2", "false")
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	// Nothing is staged, so git refuses to commit without --allow-empty
+	message := "feat: add `thing` with $(echo no) and \\n\n\nBody with \"quotes\" and 'more'."
+	if out, err := NewGitExecutor().Commit(message); err == nil {
+		t.Errorf("Commit() without changes succeeded: %s", out)
+	}
+	if out, err := NewGitExecutor("--allow-empty").Commit(message); err != nil {
+		t.Fatalf("Commit() error = %v: %s", err, out)
+	}
+	out, err := exec.Command("git", "log", "-1", "--format=%B").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimRight(string(out), "\n"); got != message {
+		t.Errorf("committed message = %q, want %q", got, message)
+	}
+}
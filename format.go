@@ -0,0 +1,120 @@
+package goodcommit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigExtensions are the supported configuration file formats, in the order they are looked
+// for when discovering configuration files. Files are decoded into the same structs whatever
+// their format, so YAML and TOML files use the same keys as the JSON ones.
+var ConfigExtensions = []string{".json", ".yaml", ".yml", ".toml"}
+
+// yamlLineRegexp matches the line number in the errors of the YAML decoder.
+var yamlLineRegexp = regexp.MustCompile(`line (\d+)`)
+
+// isJSON reports whether the configuration file at path is decoded as JSON.
+// Files with extensions other than .yaml, .yml or .toml are JSON.
+func isJSON(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".toml":
+		return false
+	}
+	return true
+}
+
+// toJSON converts the content of the configuration file at path to JSON, according to its extension.
+func toJSON(path string, raw []byte) ([]byte, error) {
+	var v any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(raw, &v); err != nil {
+			parseErr := &ConfigParseError{Path: path, Err: err}
+			if match := yamlLineRegexp.FindStringSubmatch(err.Error()); match != nil {
+				parseErr.Line, _ = strconv.Atoi(match[1])
+				parseErr.Column = 1
+			}
+			return nil, parseErr
+		}
+	case ".toml":
+		if _, err := toml.Decode(string(raw), &v); err != nil {
+			parseErr := &ConfigParseError{Path: path, Err: err}
+			var tomlErr toml.ParseError
+			if errors.As(err, &tomlErr) {
+				parseErr.Line, parseErr.Column = position(raw, int64(tomlErr.Position.Start))
+				if tomlErr.Message != "" {
+					parseErr.Err = errors.New(tomlErr.Message)
+				}
+			}
+			return nil, parseErr
+		}
+	default:
+		return raw, nil
+	}
+
+	v, err := jsonValue(v)
+	if err != nil {
+		return nil, &ConfigParseError{Path: path, Err: err}
+	}
+	return json.Marshal(v)
+}
+
+// jsonValue converts the maps with non-string keys that YAML allows to maps that can be
+// encoded to JSON.
+func jsonValue(v any) (any, error) {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			converted, err := jsonValue(value)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = converted
+		}
+		return v, nil
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for key, value := range v {
+			s, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("key %v is not a string", key)
+			}
+			converted, err := jsonValue(value)
+			if err != nil {
+				return nil, err
+			}
+			m[s] = converted
+		}
+		return m, nil
+	case []any:
+		for i, value := range v {
+			converted, err := jsonValue(value)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = converted
+		}
+		return v, nil
+	case []map[string]any:
+		// Arrays of tables in TOML
+		s := make([]any, len(v))
+		for i, value := range v {
+			converted, err := jsonValue(value)
+			if err != nil {
+				return nil, err
+			}
+			s[i] = converted
+		}
+		return s, nil
+	default:
+		return v, nil
+	}
+}
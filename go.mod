@@ -3,28 +3,30 @@ module github.com/nantli/goodcommit
 go 1.22.0
 
 require (
-    github.com/charmbracelet/huh v0.3.0
-    github.com/charmbracelet/lipgloss v0.10.0
-    golang.org/x/text v0.13.0
+	github.com/BurntSushi/toml v1.4.0
+	github.com/atotto/clipboard v0.1.4
+	github.com/charmbracelet/bubbles v0.17.2-0.20240108170749-ec883029c8e6
+	github.com/charmbracelet/bubbletea v0.25.0
+	github.com/charmbracelet/huh v0.3.0
+	github.com/charmbracelet/lipgloss v0.10.0
+	golang.org/x/text v0.13.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
-    github.com/atotto/clipboard v0.1.4 // indirect
-    github.com/aymanbagabas/go-osc52/v2 v2.0.1 // indirect
-    github.com/catppuccin/go v0.2.0 // indirect
-    github.com/charmbracelet/bubbles v0.17.2-0.20240108170749-ec883029c8e6 // indirect
-    github.com/charmbracelet/bubbletea v0.25.0 // indirect
-    github.com/containerd/console v1.0.4-0.20230313162750-1ae8d489ac81 // indirect
-    github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
-    github.com/mattn/go-isatty v0.0.20 // indirect
-    github.com/mattn/go-localereader v0.0.1 // indirect
-    github.com/mattn/go-runewidth v0.0.15 // indirect
-    github.com/muesli/ansi v0.0.0-20230316100256-276c6243b2f6 // indirect
-    github.com/muesli/cancelreader v0.2.2 // indirect
-    github.com/muesli/reflow v0.3.0 // indirect
-    github.com/muesli/termenv v0.15.2 // indirect
-    github.com/rivo/uniseg v0.4.7 // indirect
-    golang.org/x/sync v0.4.0 // indirect
-    golang.org/x/sys v0.13.0 // indirect
-    golang.org/x/term v0.13.0 // indirect
+	github.com/aymanbagabas/go-osc52/v2 v2.0.1 // indirect
+	github.com/catppuccin/go v0.2.0 // indirect
+	github.com/containerd/console v1.0.4-0.20230313162750-1ae8d489ac81 // indirect
+	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/mattn/go-localereader v0.0.1 // indirect
+	github.com/mattn/go-runewidth v0.0.15 // indirect
+	github.com/muesli/ansi v0.0.0-20230316100256-276c6243b2f6 // indirect
+	github.com/muesli/cancelreader v0.2.2 // indirect
+	github.com/muesli/reflow v0.3.0 // indirect
+	github.com/muesli/termenv v0.15.2 // indirect
+	github.com/rivo/uniseg v0.4.7 // indirect
+	golang.org/x/sync v0.4.0 // indirect
+	golang.org/x/sys v0.13.0 // indirect
+	golang.org/x/term v0.13.0 // indirect
 )
@@ -1,17 +1,31 @@
 package goodcommit
 
+import (
+	"fmt"
+
+	"github.com/atotto/clipboard"
+)
+
 type Commiter interface {
 	LoadModules(modules []Module) error
 	RunForm(accessible bool) error
+	RunHeadless(values InputValues) error
 	RunPostProcessing() error
-	PreviewCommit()
-	RenderMessage() string
+	// Review shows the message that is about to be committed and returns what the user wants
+	// to do with it.
+	Review(message string, accessible bool) (ReviewChoice, error)
+	// EditField asks again for the value of the named module, starting from the commit as it was
+	// before the last post-processing.
+	EditField(module string, accessible bool) error
+	RenderMessage() (string, error)
 }
 
 type goodCommit struct {
 	commiter Commiter
 }
 
+// Execute runs the form and the review step, until the user commits or aborts. When aborted,
+// the message is returned along with ErrAborted so it can be kept as a draft.
 func (g *goodCommit) Execute(accessible bool) (string, error) {
 	if err := g.commiter.RunForm(accessible); err != nil {
 		return "", err
@@ -19,8 +33,57 @@ func (g *goodCommit) Execute(accessible bool) (string, error) {
 	if err := g.commiter.RunPostProcessing(); err != nil {
 		return "", err
 	}
-	g.commiter.PreviewCommit()
-	return g.commiter.RenderMessage(), nil
+	message, err := g.commiter.RenderMessage()
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		choice, err := g.commiter.Review(message, accessible)
+		if err != nil {
+			return "", err
+		}
+		switch choice.Action {
+		case ReviewCommit:
+			return message, nil
+		case ReviewEditField:
+			// The message is rendered again from the values, edits made in the editor are lost
+			if err := g.commiter.EditField(choice.Module, accessible); err != nil {
+				return "", err
+			}
+			if err := g.commiter.RunPostProcessing(); err != nil {
+				return "", err
+			}
+			if message, err = g.commiter.RenderMessage(); err != nil {
+				return "", err
+			}
+		case ReviewEditMessage:
+			edited, err := EditMessage(message)
+			if err != nil {
+				return "", err
+			}
+			message = edited
+		case ReviewCopy:
+			// Not having a clipboard, e.g. over SSH, is not a reason to lose the commit
+			if err := clipboard.WriteAll(message); err != nil {
+				fmt.Println("Error copying the message to the clipboard:", err)
+			}
+		case ReviewAbort:
+			return message, ErrAborted
+		}
+	}
+}
+
+// ExecuteHeadless fills the commit from the given values instead of running the form,
+// so goodcommit can be used from scripts and CI where there is no terminal.
+func (g *goodCommit) ExecuteHeadless(values InputValues) (string, error) {
+	if err := g.commiter.RunHeadless(values); err != nil {
+		return "", err
+	}
+	if err := g.commiter.RunPostProcessing(); err != nil {
+		return "", err
+	}
+	return g.commiter.RenderMessage()
 }
 
 func New(c Commiter) *goodCommit {
@@ -0,0 +1,389 @@
+package goodcommiter
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+	gc "github.com/nantli/goodcommit"
+)
+
+// formState is shared by the pages of a form.
+type formState struct {
+	commit *gc.Commit
+	// starting is set while the form initializes its groups, huh then inits every group and a
+	// group whose fields are all skipped would move the form past the first page.
+	starting bool
+	// frozen is set while a key is handled, so fields are only rebuilt when moving between them,
+	// once the field being left has stored its value in the commit.
+	frozen bool
+	// height is the height of the terminal, if known.
+	height int
+	// draft, if set, is saved whenever the commit changes while moving through the form.
+	draft *gc.Draft
+	saved string
+}
+
+// save saves the commit to the draft, if it changed since the last save. The draft is a best
+// effort, failing to save it doesn't stop the form.
+func (s *formState) save() {
+	if s.draft == nil {
+		return
+	}
+	if commit := fingerprint(s.commit); commit != s.saved {
+		s.saved = commit
+		_ = s.draft.Save(*s.commit)
+	}
+}
+
+// formPage is a page of the form, a huh.Group whose fields are built again from the commit when
+// the page is entered, so they reflect the answers given on the previous pages.
+type formPage struct {
+	state  *formState
+	fields []*lazyField
+	group  *huh.Group
+}
+
+func newFormPage(state *formState, modules []gc.Module) *formPage {
+	p := &formPage{state: state}
+	var fields []huh.Field
+	for _, m := range modules {
+		f := &lazyField{module: m, page: p}
+		f.build()
+		p.fields = append(p.fields, f)
+		fields = append(fields, f)
+	}
+	p.group = huh.NewGroup(fields...).WithHideFunc(p.hidden)
+	return p
+}
+
+// refresh rebuilds the fields of the page built from an older commit.
+func (p *formPage) refresh() {
+	if p.state.frozen {
+		return
+	}
+	commit := fingerprint(p.state.commit)
+	rebuilt := false
+	for _, f := range p.fields {
+		// The field being edited changes the commit as the user types, it must not be replaced
+		if f.built != commit && !f.editing {
+			f.build()
+			rebuilt = true
+		}
+	}
+	if !rebuilt {
+		return
+	}
+
+	// The group sizes itself when created, so it has to be resized for the new fields
+	height := 0
+	for _, f := range p.fields {
+		height += lipgloss.Height(f.View()) + 1
+	}
+	if p.state.height > 0 && height > p.state.height {
+		p.group.WithHeight(p.state.height)
+	} else {
+		p.group.WithHeight(height + 1)
+	}
+}
+
+// hidden tells if the page has nothing to show but pinned modules.
+func (p *formPage) hidden() bool {
+	p.refresh()
+	for _, f := range p.fields {
+		if f.field != nil && !f.module.Config().Pinned {
+			return false
+		}
+	}
+	return true
+}
+
+// err returns the first error of the modules of the page when building their fields.
+func (p *formPage) err() error {
+	for _, f := range p.fields {
+		if f.err != nil {
+			return f.err
+		}
+	}
+	return nil
+}
+
+// fingerprint identifies the values of the commit.
+func fingerprint(commit *gc.Commit) string {
+	b, _ := json.Marshal(commit)
+	return string(b)
+}
+
+// lazyField is the field of a module, built by its NewField. It is skipped when the module has
+// no field for the commit, and shows the error when the module fails to build it, so the user
+// can go back and change the answers that caused it.
+type lazyField struct {
+	module  gc.Module
+	page    *formPage
+	field   huh.Field
+	err     error
+	built   string // Fingerprint of the commit the field was built from.
+	editing bool   // Set when the field gets keys, until it is left.
+
+	// Settings given by the form, applied to every new field
+	theme      *huh.Theme
+	keymap     *huh.KeyMap
+	accessible bool
+	width      int
+	height     int
+	position   huh.FieldPosition
+}
+
+// build replaces the field with a new one built from the current commit. A module without a
+// field for the commit, or whose values are no longer among the options of its field, is reset
+// so the values of an earlier answer don't end up in the message, see gc.Resetter.
+func (f *lazyField) build() {
+	commit := f.page.state.commit
+	defer func() { f.built = fingerprint(commit) }()
+	f.field, f.err = nil, nil
+	if !f.module.Config().Shown(commit) {
+		f.reset(false)
+		return
+	}
+	f.reset(true)
+	field, err := f.module.NewField(commit)
+	if err != nil {
+		f.err = fmt.Errorf("%s: %w", f.module.Name(), err)
+		field = huh.NewNote().Title("⚠️・" + f.module.Name()).Description(err.Error())
+	}
+	if field == nil {
+		f.reset(false)
+		return
+	}
+	if f.theme != nil {
+		field = field.WithTheme(f.theme)
+	}
+	if f.keymap != nil {
+		field = field.WithKeyMap(f.keymap)
+	}
+	if f.height > 0 {
+		field = field.WithHeight(f.height)
+	}
+	f.field = field.WithAccessible(f.accessible).WithWidth(f.width).WithPosition(f.position)
+}
+
+// reset lets the module remove its values from the commit, if it is a gc.Resetter.
+func (f *lazyField) reset(shown bool) {
+	if r, ok := f.module.(gc.Resetter); ok {
+		r.Reset(f.page.state.commit, shown)
+	}
+}
+
+// Init is only called when the fields are run one by one, in accessible mode.
+func (f *lazyField) Init() tea.Cmd {
+	f.page.state.save()
+	f.page.refresh()
+	if f.field == nil {
+		return nil
+	}
+	return f.field.Init()
+}
+
+func (f *lazyField) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// Only the current field gets keys, huh focuses the first field of every page on start
+	if _, ok := msg.(tea.KeyMsg); ok {
+		f.editing = true
+	}
+	if f.field == nil {
+		return f, nil
+	}
+	m, cmd := f.field.Update(msg)
+	f.field = m.(huh.Field)
+	return f, cmd
+}
+
+func (f *lazyField) View() string {
+	if f.field == nil {
+		return ""
+	}
+	return f.field.View()
+}
+
+func (f *lazyField) Blur() tea.Cmd {
+	f.editing = false
+	if f.field == nil {
+		return nil
+	}
+	return f.field.Blur()
+}
+
+func (f *lazyField) Focus() tea.Cmd {
+	if f.field == nil {
+		return nil
+	}
+	return f.field.Focus()
+}
+
+func (f *lazyField) Error() error {
+	if f.field == nil {
+		return nil
+	}
+	return f.field.Error()
+}
+
+func (f *lazyField) Run() error {
+	if f.field == nil {
+		return nil
+	}
+	return f.field.Run()
+}
+
+// Skip is called before a field is entered, so its page is refreshed first.
+func (f *lazyField) Skip() bool {
+	f.page.refresh()
+	if f.page.state.starting {
+		return false
+	}
+	return f.field == nil || f.field.Skip()
+}
+
+func (f *lazyField) KeyBinds() []key.Binding {
+	if f.field == nil {
+		return nil
+	}
+	return f.field.KeyBinds()
+}
+
+func (f *lazyField) WithTheme(theme *huh.Theme) huh.Field {
+	f.theme = theme
+	if f.field != nil {
+		f.field = f.field.WithTheme(theme)
+	}
+	return f
+}
+
+func (f *lazyField) WithAccessible(accessible bool) huh.Field {
+	f.accessible = accessible
+	if f.field != nil {
+		f.field = f.field.WithAccessible(accessible)
+	}
+	return f
+}
+
+func (f *lazyField) WithKeyMap(keymap *huh.KeyMap) huh.Field {
+	f.keymap = keymap
+	if f.field != nil {
+		f.field = f.field.WithKeyMap(keymap)
+	}
+	return f
+}
+
+func (f *lazyField) WithWidth(width int) huh.Field {
+	f.width = width
+	if f.field != nil {
+		f.field = f.field.WithWidth(width)
+	}
+	return f
+}
+
+func (f *lazyField) WithHeight(height int) huh.Field {
+	f.height = height
+	if f.field != nil {
+		f.field = f.field.WithHeight(height)
+	}
+	return f
+}
+
+func (f *lazyField) WithPosition(position huh.FieldPosition) huh.Field {
+	f.position = position
+	if f.field != nil {
+		f.field = f.field.WithPosition(position)
+	}
+	return f
+}
+
+func (f *lazyField) GetKey() string {
+	if f.field == nil {
+		return ""
+	}
+	return f.field.GetKey()
+}
+
+func (f *lazyField) GetValue() any {
+	if f.field == nil {
+		return nil
+	}
+	return f.field.GetValue()
+}
+
+// formModel runs a huh.Form with lazy fields, see formState.
+type formModel struct {
+	form  *huh.Form
+	state *formState
+}
+
+func (m *formModel) Init() tea.Cmd {
+	m.state.starting = true
+	defer func() { m.state.starting = false }()
+	return m.form.Init()
+}
+
+func (m *formModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		m.state.frozen = true
+		defer func() { m.state.frozen = false }()
+	case tea.WindowSizeMsg:
+		m.state.height = msg.Height
+	}
+	form, cmd := m.form.Update(msg)
+	m.form = form.(*huh.Form)
+	if m.form.State != huh.StateNormal {
+		return m, tea.Quit
+	}
+	if !m.state.frozen {
+		// Pages may have been shown or hidden, e.g. "enter next" may now be "enter submit"
+		m.form.UpdateFieldPositions()
+		m.state.save()
+	}
+	return m, cmd
+}
+
+func (m *formModel) View() string {
+	return m.form.View()
+}
+
+// runForm runs a form made of the given pages, and returns the errors of the modules that
+// could not build their fields.
+func runForm(state *formState, pages []*formPage, accessible bool) error {
+	if len(pages) == 0 {
+		return nil
+	}
+	var groups []*huh.Group
+	for _, p := range pages {
+		groups = append(groups, p.group)
+	}
+	form := huh.NewForm(groups...).
+		WithTheme(huh.ThemeCharm()).
+		WithAccessible(accessible)
+
+	// Whatever was entered is kept, even when the form is aborted
+	defer state.save()
+	if accessible {
+		if err := form.Run(); err != nil {
+			return err
+		}
+	} else {
+		if _, err := tea.NewProgram(&formModel{form: form, state: state}).Run(); err != nil {
+			return err
+		}
+		if form.State == huh.StateAborted {
+			return huh.ErrUserAborted
+		}
+	}
+
+	for _, p := range pages {
+		if err := p.err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
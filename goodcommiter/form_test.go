@@ -0,0 +1,75 @@
+package goodcommiter
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	gc "github.com/nantli/goodcommit"
+	"github.com/nantli/goodcommit/breaking"
+	"github.com/nantli/goodcommit/breakingmsg"
+	"github.com/nantli/goodcommit/scopes"
+)
+
+// newModule returns the module loaded with the given options.
+func newModule(t *testing.T, m gc.Module, options string) gc.Module {
+	t.Helper()
+	config := m.Config()
+	config.Active = true
+	if options != "" {
+		config.Options = json.RawMessage(options)
+	}
+	m.SetConfig(config)
+	if err := m.LoadConfig(); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// TestBuildResetsHiddenValues checks that the values of an earlier answer are removed when the
+// fields are built again for a type that doesn't allow them.
+func TestBuildResetsHiddenValues(t *testing.T) {
+	modules := []gc.Module{
+		newModule(t, scopes.New(), `{"scopes": [
+			{"id": "api", "name": "API", "emoji": "🔌", "conditional": ["feat", "fix"]},
+			{"id": "site", "name": "Site", "emoji": "🌐", "conditional": ["*"]}
+		]}`),
+		newModule(t, breaking.New(), ""),
+		newModule(t, breakingmsg.New(), ""),
+	}
+	c, err := New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.commit.Type = "feat"
+	c.commit.Description = "add thing"
+	if err := c.LoadModules(modules); err != nil {
+		t.Fatal(err)
+	}
+	c.commit.Scopes = []string{"api", "site"}
+	c.commit.Breaking = true
+	*c.commit.Extras["breakingmsg"] = "the v1 API is gone"
+
+	// Going back to change the type builds the fields of the next pages again
+	c.commit.Type = "docs"
+	state := &formState{commit: &c.commit}
+	newFormPage(state, modules)
+
+	if c.commit.Breaking {
+		t.Error("the commit is still breaking")
+	}
+	if want := []string{"site"}; !slices.Equal(c.commit.Scopes, want) {
+		t.Errorf("scopes = %q, want %q", c.commit.Scopes, want)
+	}
+	if err := c.RunPostProcessing(); err != nil {
+		t.Fatal(err)
+	}
+	message, err := c.RenderMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(message, "docs(🌐): add thing") || strings.Contains(message, "BREAKING CHANGE") {
+		t.Errorf("message keeps the values of the feat commit:\n%s", message)
+	}
+}
@@ -4,6 +4,7 @@
 package goodcommiter
 
 import (
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
@@ -14,8 +15,22 @@ import (
 )
 
 type goodCommiter struct {
-	modules []gc.Module
-	commit  gc.Commit
+	modules  []gc.Module
+	commit   gc.Commit
+	values   gc.Commit // The commit before the last post-processing.
+	draft    *gc.Draft
+	template *gc.Template
+}
+
+// SetTemplate sets the template the message is rendered with, instead of gc.DefaultTemplate.
+func (c *goodCommiter) SetTemplate(template *gc.Template) {
+	c.template = template
+}
+
+// SaveDraft makes the form save the commit to the draft as it is filled, so it can be resumed if
+// the form is interrupted. See NewFromCommit to resume a draft.
+func (c *goodCommiter) SaveDraft(draft *gc.Draft) {
+	c.draft = draft
 }
 
 func (c *goodCommiter) RunForm(accessible bool) error {
@@ -55,8 +70,10 @@ func (c *goodCommiter) RunForm(accessible bool) error {
 	}
 	sort.Ints(pages) // Sort the pages
 
-	var groups []*huh.Group
-	pageHasNonPinned := make(map[int]bool) // Track if a page has non-pinned modules
+	// All the pages up to a checkpoint go in the same form, where the user can go back and forth.
+	// Fields are built when their page is entered, so they always see the previous answers.
+	state := &formState{commit: &c.commit, draft: c.draft}
+	var formPages []*formPage
 
 	for _, page := range pages { // Iterate over sorted pages
 		// Sort the modules by position
@@ -71,163 +88,228 @@ func (c *goodCommiter) RunForm(accessible bool) error {
 			return mi.Config().Page < mj.Config().Page
 		})
 
-		var fields []huh.Field
-		// Add the fields from the modules to the group
+		var modules []gc.Module
+		hasNonPinned := false // Pages with only pinned modules are not shown
 		for _, m := range modulesByPage[page] {
-
-			field, err := (*m).NewField(&c.commit)
-			if err != nil {
-				return err
-			}
-
-			if !(*m).Config().Pinned && field != nil && (*m).Config().Active {
-				pageHasNonPinned[page] = true // Mark page as having non-pinned modules
-			}
-
-			if field != nil {
-				fields = append(fields, field)
+			modules = append(modules, *m)
+			if !(*m).Config().Pinned {
+				hasNonPinned = true
 			}
 		}
-
-		// Only create a group if the page has non-pinned modules
-		if pageHasNonPinned[page] {
-			group := huh.NewGroup(fields...)
-			groups = append(groups, group)
+		if hasNonPinned {
+			formPages = append(formPages, newFormPage(state, modules))
 		}
 
-		// Check if any module in the page has the checkpoint set to true
+		// A checkpoint is a barrier, the answers up to it can't be changed after passing it
 		for _, m := range modulesByPage[page] {
 			if (*m).Config().Checkpoint {
-				// Create the form with the current groups
-				form := huh.NewForm(groups...).
-					WithTheme(huh.ThemeCharm()).
-					WithAccessible(accessible)
-
-				// Run the form and check for errors
-				if err := form.Run(); err != nil {
+				if err := runForm(state, formPages, accessible); err != nil {
 					return err
 				}
 
-				// Start a new set of groups for the next pages
-				groups = []*huh.Group{}
+				// Start a new set of pages for the next form
+				formPages = nil
 				break
 			}
 		}
 	}
 
-	// Create and run the form with the remaining groups
-	form := huh.NewForm(groups...).
-		WithTheme(huh.ThemeCharm()).
-		WithAccessible(accessible)
-
-	err := form.Run()
-
-	return err
+	// Create and run the form with the remaining pages
+	return runForm(state, formPages, accessible)
 }
 
-func (c *goodCommiter) RunPostProcessing() error {
+// RunHeadless fills the commit with the given values, using the inputs declared by the
+// active modules in priority order. All missing or invalid values are reported at once.
+// When amending, required values default to the ones of the amended commit. Modules whose when
+// expression doesn't hold, given the values set before them, take no values. The filled commit
+// must then follow the rules of the modules, see gc.Validate.
+func (c *goodCommiter) RunHeadless(values gc.InputValues) error {
+	var errs []error
+	known := make(map[string]bool)
+
 	for i := 0; i < 100; i++ {
 		for _, m := range c.modules {
 			if m.Config().Priority != i {
 				continue
 			}
-			if err := m.PostProcess(&c.commit); err != nil {
-				return err
+			im, ok := m.(gc.InputModule)
+			if !ok {
+				if err := c.checkExternal(m); err != nil {
+					errs = append(errs, err)
+				}
+				continue
+			}
+			for _, in := range im.Inputs() {
+				known[in.Key] = true
+				if !m.IsActive() {
+					continue
+				}
+				v := values[in.Key]
+				if !m.Config().Shown(&c.commit) {
+					if len(v) > 0 {
+						errs = append(errs, fmt.Errorf("%s: value given for %q but when %q does not hold", m.Name(), in.Key, m.Config().When))
+					}
+					continue
+				}
+				if len(v) == 0 {
+					if in.Required && !c.commit.Amend {
+						errs = append(errs, fmt.Errorf("%s: missing required value %q (use --%s or %s)", m.Name(), in.Key, in.Key, gc.InputEnv(in.Key)))
+					}
+					continue
+				}
+				if err := in.Set(&c.commit, v); err != nil {
+					errs = append(errs, fmt.Errorf("%s: invalid value for %q: %w", m.Name(), in.Key, err))
+				}
 			}
 		}
 	}
-	return nil
-}
 
-func (c *goodCommiter) PreviewCommit() {
-	var sb strings.Builder
-	keywordStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FFD700"))
-	alertStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000"))
-	footerStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("#00D4F4"))
-
-	// Determine the style to use based on whether the commit type includes an exclamation mark
-	var typeStyle lipgloss.Style
-	if strings.Contains(c.commit.Type, "!") {
-		typeStyle = alertStyle
-	} else {
-		typeStyle = keywordStyle
-	}
-
-	breakingChangeIndicator := ""
-	if c.commit.Breaking {
-		breakingChangeIndicator = "!"
-	}
-
-	// Use the determined style for the commit type
-	fmt.Fprintf(&sb,
-		"%s\n\nType: %s%s\nScope: %s\nDescription: %s\nBody:\n\n%s\n",
-		lipgloss.NewStyle().Bold(true).Render("COMMIT SUMMARY 💎"),
-		typeStyle.Render(c.commit.Type), // Apply the conditional styling here
-		breakingChangeIndicator,
-		keywordStyle.Render(c.commit.Scope),
-		keywordStyle.Render(c.commit.Description),
-		lipgloss.NewStyle().Italic(true).Render(c.commit.Body),
-	)
-
-	if len(c.commit.CoAuthoredBy) > 0 {
-		var coauthors string
-		// build coauthors to gather all entries in CoAuthoredBy
-		for _, coauthor := range c.commit.CoAuthoredBy {
-			coauthors += fmt.Sprintf("\nCo-authored-by: %s", coauthor)
+	for key := range values {
+		if !known[key] {
+			errs = append(errs, fmt.Errorf("unknown input %q", key))
 		}
-		fmt.Fprintf(&sb, "%s", footerStyle.Render(coauthors))
 	}
-
-	if c.commit.Footer != "" {
-		fmt.Fprintf(&sb, "%s", footerStyle.Render(c.commit.Footer))
+	if len(errs) > 0 {
+		return errors.Join(errs...)
 	}
 
-	fmt.Fprintf(&sb, "\n\n%s", lipgloss.NewStyle().Bold(true).Render("He's alright, he's a GOODCOMMIT!"))
-
-	fmt.Println(
-		lipgloss.NewStyle().
-			Width(60).
-			BorderStyle(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("#FFD700")).
-			Padding(1, 2).
-			Render(sb.String()),
-	)
+	// The values kept from the amended commit were not given to the inputs, so the whole commit
+	// is checked against the rules of the modules, as lint would check the message
+	for _, v := range gc.Validate(c.modules, &c.commit) {
+		errs = append(errs, errors.New(v.String()))
+	}
+	return errors.Join(errs...)
 }
 
-func (c *goodCommiter) RenderMessage() string {
-	var scopeStr string
-	if c.commit.Scope != "" {
-		scopeStr = fmt.Sprintf("(%s)", c.commit.Scope)
+// checkExternal returns an error if the module is an external module that asks for a value,
+// which can't be given without a form. When amending, the values of HEAD are kept instead.
+func (c *goodCommiter) checkExternal(m gc.Module) error {
+	if m.Config().Exec == "" || !m.IsActive() || !m.Config().Shown(&c.commit) || c.commit.Amend {
+		return nil
 	}
+	field, err := m.NewField(&c.commit)
+	if err != nil {
+		return fmt.Errorf("%s: %w", m.Name(), err)
+	}
+	if _, note := field.(*huh.Note); field == nil || note {
+		return nil
+	}
+	return fmt.Errorf("%s: external modules can only be filled in the form, run goodcommit interactively or deactivate the module", m.Name())
+}
 
-	breakingChangeIndicator := ""
-	if c.commit.Breaking {
-		breakingChangeIndicator = "!"
+// RunPostProcessing lets the modules add their parts to the message. The commit as filled is
+// kept, so a field can be edited afterwards without the parts being added twice.
+func (c *goodCommiter) RunPostProcessing() error {
+	c.values = c.commit.Clone()
+	for i := 0; i < 100; i++ {
+		for _, m := range c.modules {
+			if m.Config().Priority != i || !m.IsActive() {
+				continue
+			}
+			if err := m.PostProcess(&c.commit); err != nil {
+				return err
+			}
+		}
 	}
+	return nil
+}
 
-	commitMsg := fmt.Sprintf("%s%s%s: %s\n\n%s\n", c.commit.Type, scopeStr, breakingChangeIndicator, c.commit.Description, c.commit.Body)
+// Review shows the message with a menu of what to do with it: commit, edit a field, edit the
+// message in the editor, copy it to the clipboard or abort.
+func (c *goodCommiter) Review(message string, accessible bool) (gc.ReviewChoice, error) {
+	var choice gc.ReviewChoice
+	var action string
+	form := huh.NewForm(huh.NewGroup(
+		huh.NewNote().
+			Title("COMMIT MESSAGE 💎").
+			Description(lipgloss.NewStyle().
+				BorderStyle(lipgloss.RoundedBorder()).
+				BorderForeground(lipgloss.Color("#FFD700")).
+				Padding(0, 1).
+				Render(strings.TrimRight(message, "\n"))),
+		huh.NewSelect[string]().
+			Title("He's alright, he's a GOODCOMMIT!").
+			Options(
+				huh.NewOption("✅ Commit", "commit"),
+				huh.NewOption("✏️  Edit a field", "field"),
+				huh.NewOption("📝 Edit the message in "+gc.Editor(), "editor"),
+				huh.NewOption("📋 Copy to clipboard", "copy"),
+				huh.NewOption("🗑️  Abort and keep a draft", "abort"),
+			).
+			Value(&action),
+	)).WithTheme(huh.ThemeCharm()).WithAccessible(accessible)
+	if err := form.Run(); err != nil {
+		return choice, err
+	}
 
-	if len(c.commit.CoAuthoredBy) > 0 {
-		var coauthors string
-		// build coauthors to gather all entries in CoAuthoredBy
-		for _, coauthor := range c.commit.CoAuthoredBy {
-			coauthors += fmt.Sprintf("\nCo-authored-by: %s", coauthor)
+	switch action {
+	case "commit":
+		choice.Action = gc.ReviewCommit
+	case "editor":
+		choice.Action = gc.ReviewEditMessage
+	case "copy":
+		choice.Action = gc.ReviewCopy
+	case "abort":
+		choice.Action = gc.ReviewAbort
+	case "field":
+		choice.Action = gc.ReviewEditField
+		var options []huh.Option[string]
+		for _, m := range c.modules {
+			if m.IsActive() && !m.Config().Pinned && m.Config().Shown(&c.values) {
+				options = append(options, huh.NewOption(m.Name(), m.Name()))
+			}
+		}
+		err := huh.NewSelect[string]().
+			Title("Which field do you want to edit?").
+			Options(options...).
+			Value(&choice.Module).
+			WithTheme(huh.ThemeCharm()).
+			WithAccessible(accessible).
+			Run()
+		if err != nil {
+			return choice, err
 		}
-		commitMsg += coauthors
 	}
+	return choice, nil
+}
 
-	if c.commit.Footer != "" {
-		commitMsg += c.commit.Footer
+// EditField runs the form again with only the field of the named module, over the commit as
+// it was filled before the post-processing.
+func (c *goodCommiter) EditField(module string, accessible bool) error {
+	c.commit = c.values.Clone()
+	state := &formState{commit: &c.commit, draft: c.draft}
+	for _, m := range c.modules {
+		if m.Name() == module && m.IsActive() {
+			if err := runForm(state, []*formPage{newFormPage(state, []gc.Module{m})}, accessible); err != nil {
+				return err
+			}
+			// The fields of the other modules are built again, so those that depend on the edited
+			// one are reset, e.g. the breaking flag when the type changes to docs
+			var others []gc.Module
+			for _, o := range c.modules {
+				if o != m && o.IsActive() && !o.Config().Pinned {
+					others = append(others, o)
+				}
+			}
+			newFormPage(state, others)
+			return nil
+		}
 	}
+	return fmt.Errorf("no active module %s", module)
+}
 
-	return commitMsg
+// RenderMessage renders the commit with the message template, see SetTemplate.
+func (c *goodCommiter) RenderMessage() (string, error) {
+	return c.template.Render(c.commit)
 }
 
 func (c *goodCommiter) LoadModules(modules []gc.Module) error {
-	// run InitCommitInfo from all modules in priority order
+	gc.BindModules(modules)
+
+	// run InitCommitInfo from all active modules in priority order
 	for i := 0; i < 100; i++ {
 		for _, m := range modules {
-			if m.Config().Priority > i {
+			if m.Config().Priority != i || !m.IsActive() {
 				continue
 			}
 			if err := m.InitCommitInfo(&c.commit); err != nil {
@@ -242,5 +324,16 @@ func (c *goodCommiter) LoadModules(modules []gc.Module) error {
 func New() (*goodCommiter, error) {
 	commit := gc.Commit{Extras: make(map[string]*string)}
 
-	return &goodCommiter{modules: []gc.Module{}, commit: commit}, nil
+	return &goodCommiter{modules: []gc.Module{}, commit: commit, template: gc.DefaultTemplate()}, nil
+}
+
+// NewFromCommit returns a commiter that starts from the given commit instead of a blank one,
+// e.g. the parsed message of HEAD when amending or the commit of a draft being resumed. The
+// commit is expected as returned by gc.Parse, each module turns its part back into the values of
+// its field in InitCommitInfo, leaving the values of a draft as they are.
+func NewFromCommit(commit gc.Commit) (*goodCommiter, error) {
+	if commit.Extras == nil {
+		commit.Extras = make(map[string]*string)
+	}
+	return &goodCommiter{modules: []gc.Module{}, commit: commit, template: gc.DefaultTemplate()}, nil
 }
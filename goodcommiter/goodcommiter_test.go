@@ -0,0 +1,98 @@
+package goodcommiter
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	gc "github.com/nantli/goodcommit"
+	"github.com/nantli/goodcommit/breaking"
+	"github.com/nantli/goodcommit/breakingmsg"
+	"github.com/nantli/goodcommit/description"
+	_ "github.com/nantli/goodcommit/plugin"
+	"github.com/nantli/goodcommit/scopes"
+	"github.com/nantli/goodcommit/types"
+)
+
+// TestRunHeadlessValidatesAmendedValues checks that the values kept from HEAD when amending follow
+// the rules of the modules for the values given.
+func TestRunHeadlessValidatesAmendedValues(t *testing.T) {
+	tests := []struct {
+		name   string
+		values gc.InputValues
+		want   []string // Parts of the error, none if it should succeed.
+	}{
+		{"same type", gc.InputValues{"description": {"add other thing"}}, nil},
+		{"docs", gc.InputValues{"type": {"docs"}}, []string{"breaking", `scope "api" is not valid for commit type "docs"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modules := []gc.Module{
+				newModule(t, types.New(), `{"types": [{"id": "feat", "name": "Feat"}, {"id": "docs", "name": "Docs"}]}`),
+				newModule(t, scopes.New(), `{"scopes": [{"id": "api", "name": "API", "emoji": "🔌", "conditional": ["feat", "fix"]}]}`),
+				newModule(t, description.New(), ""),
+				newModule(t, breaking.New(), ""),
+				newModule(t, breakingmsg.New(), ""),
+			}
+			head, err := gc.Parse("feat(🔌)!: add thing\n\nSCOPE: API\n\nBREAKING CHANGE: Gone.\n")
+			if err != nil {
+				t.Fatal(err)
+			}
+			head.Amend = true
+			c, err := NewFromCommit(head)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := c.LoadModules(modules); err != nil {
+				t.Fatal(err)
+			}
+
+			err = c.RunHeadless(tt.values)
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("RunHeadless() error = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("RunHeadless() succeeded, want the kept values to be reported")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("RunHeadless() error = %v, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+// TestRunHeadlessExternalModule checks that a run without a form fails when an external module
+// asks for a value, instead of leaving its field empty, unless the value is kept when amending.
+func TestRunHeadlessExternalModule(t *testing.T) {
+	if _, err := exec.LookPath("python3"); err != nil {
+		t.Skip("python3 is needed to run the reference module")
+	}
+	for _, amend := range []bool{false, true} {
+		m, err := gc.NewModule(gc.ModuleConfig{Name: "ticket", Active: true, Exec: "../examples/plugins/ticket.py"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		m = newModule(t, m, "")
+		defer gc.CloseModules([]gc.Module{m})
+
+		c, err := NewFromCommit(gc.Commit{Type: "feat", Description: "add thing", Amend: amend})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := c.LoadModules([]gc.Module{m}); err != nil {
+			t.Fatal(err)
+		}
+		err = c.RunHeadless(gc.InputValues{})
+		if amend && err != nil {
+			t.Errorf("RunHeadless() when amending error = %v", err)
+		}
+		if !amend && (err == nil || !strings.Contains(err.Error(), "can only be filled in the form")) {
+			t.Errorf("RunHeadless() error = %v, want the external module to be reported", err)
+		}
+	}
+}
@@ -4,10 +4,9 @@
 package greetings
 
 import (
-	"bytes"
 	"fmt"
 	"os"
-	"os/exec"
+	"strings"
 
 	"github.com/charmbracelet/huh"
 	gc "github.com/nantli/goodcommit"
@@ -27,17 +26,21 @@ func (g *greetings) LoadConfig() error {
 // NewField returns a huh.Confirm field that asks the user to confirm the staged files.
 // If the user confirms, the flow continues. If the user does not confirm, the commit is aborted.
 func (g *greetings) NewField(commit *gc.Commit) (huh.Field, error) {
-	stagedFiles, err := g.getStagedFiles()
+	stagedFiles, err := gc.StagedFiles()
 	if err != nil {
 		return nil, fmt.Errorf("error getting staged files: %w", err)
 	}
 
+	if len(stagedFiles) == 0 && commit.Amend {
+		return huh.NewNote().Title("🐝・Amending the last commit").Description("\nNo staged files, only the commit message will change."), nil
+	}
+
 	if len(stagedFiles) == 0 {
 		return nil, fmt.Errorf("no staged files found")
 	}
 
 	// Display staged files as a simple text field in the form
-	stagedFilesText := fmt.Sprintf("\nStaged Files:\n%s", stagedFiles)
+	stagedFilesText := fmt.Sprintf("\nStaged Files:\n%s\n", strings.Join(stagedFiles, "\n"))
 	return huh.NewConfirm().Title("🐝・Do you want to commit these files?").Description(stagedFilesText).Validate(
 		func(b bool) error {
 			if b {
@@ -62,18 +65,7 @@ func (g *greetings) SetConfig(config gc.ModuleConfig) {
 }
 
 func (g *greetings) Name() string {
-	return MODULE_NAME
-}
-
-func (g *greetings) getStagedFiles() (string, error) {
-	cmd := exec.Command("git", "diff", "--cached", "--name-only")
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	err := cmd.Run()
-	if err != nil {
-		return "", err
-	}
-	return out.String(), nil
+	return g.config.Name
 }
 
 func (g *greetings) InitCommitInfo(commit *gc.Commit) error {
@@ -91,3 +83,7 @@ func (g *greetings) IsActive() bool {
 func New() gc.Module {
 	return &greetings{config: gc.ModuleConfig{Name: MODULE_NAME}}
 }
+
+func init() {
+	gc.Register(MODULE_NAME, New)
+}
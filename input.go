@@ -0,0 +1,114 @@
+package goodcommit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// InputKind tells how the value of an Input is given.
+type InputKind int
+
+const (
+	// InputString is a single text value, e.g. --description "add parser".
+	InputString InputKind = iota
+	// InputList is a value that can be repeated or comma separated, e.g. --scope api,core.
+	InputList
+	// InputBool is a switch, e.g. --breaking.
+	InputBool
+)
+
+// Input declares a value that a module accepts when goodcommit runs without a form.
+// The Key is used as the flag name, the key of the JSON object given with --from-json
+// and, upper cased, as the GOODCOMMIT_<KEY> environment variable.
+type Input struct {
+	Key      string
+	Short    string // Optional one letter alias for the flag.
+	Kind     InputKind
+	Usage    string
+	Required bool
+	// Set stores the given values in the commit, it is only called when at least one value is given.
+	Set func(commit *Commit, values []string) error
+}
+
+// InputModule is implemented by modules that can be filled without a huh.Field,
+// for example when goodcommit is run in non-interactive mode by a CI bot.
+type InputModule interface {
+	Inputs() []Input
+}
+
+// InputValues holds the values given for each input key.
+type InputValues map[string][]string
+
+// Merge copies the values from other into v, replacing the keys already present.
+func (v InputValues) Merge(other InputValues) {
+	for key, values := range other {
+		v[key] = values
+	}
+}
+
+// InputEnv returns the name of the environment variable that holds the value for the given input key.
+func InputEnv(key string) string {
+	return "GOODCOMMIT_" + strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+}
+
+// EnvInputValues reads the values of the given inputs from their environment variables.
+func EnvInputValues(inputs []Input) InputValues {
+	values := InputValues{}
+	for _, in := range inputs {
+		env, ok := os.LookupEnv(InputEnv(in.Key))
+		if !ok {
+			continue
+		}
+		if in.Kind == InputList {
+			values[in.Key] = SplitInputList(env)
+		} else {
+			values[in.Key] = []string{env}
+		}
+	}
+	return values
+}
+
+// ParseInputValues parses a JSON object with input keys into InputValues.
+// Values can be strings, booleans, numbers or lists of those.
+// Example:
+//
+//	{
+//	    "type": "feat",
+//	    "scope": ["api", "core"],
+//	    "description": "add headless mode",
+//	    "breaking": false
+//	}
+func ParseInputValues(raw []byte) (InputValues, error) {
+	var obj map[string]any
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return nil, fmt.Errorf("error parsing input values: %w", err)
+	}
+
+	values := InputValues{}
+	for key, value := range obj {
+		switch v := value.(type) {
+		case nil:
+			continue
+		case []any:
+			for _, item := range v {
+				values[key] = append(values[key], fmt.Sprint(item))
+			}
+		default:
+			values[key] = []string{fmt.Sprint(v)}
+		}
+	}
+	return values, nil
+}
+
+// SplitInputList splits a comma separated list value, dropping empty entries.
+func SplitInputList(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
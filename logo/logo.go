@@ -3,7 +3,9 @@
 package logo
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 
 	"github.com/charmbracelet/huh"
@@ -23,6 +25,9 @@ type logo struct {
 func (l *logo) LoadConfig() error {
 	if l.config.Path != "" {
 		raw, err := os.ReadFile(l.config.Path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return &gc.ConfigNotFoundError{Path: l.config.Path, Err: err}
+		}
 		if err != nil {
 			return fmt.Errorf("failed to read logo file: %w", err)
 		}
@@ -55,7 +60,7 @@ func (l *logo) SetConfig(config gc.ModuleConfig) {
 }
 
 func (l *logo) Name() string {
-	return MODULE_NAME
+	return l.config.Name
 }
 
 func (l *logo) IsActive() bool {
@@ -75,3 +80,7 @@ func New() gc.Module {
 		asciiArt: "", // Initialize with an empty string
 	}
 }
+
+func init() {
+	gc.Register(MODULE_NAME, New)
+}
@@ -0,0 +1,84 @@
+package goodcommit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SavedMessage is a commit message that was not committed, because the commit failed or was
+// aborted in the review step. It is saved in the git directory of the repository, so it can be
+// committed later with goodcommit --retry.
+type SavedMessage struct {
+	ID      string    `json:"id"`
+	Branch  string    `json:"branch,omitempty"`
+	Saved   time.Time `json:"saved"`
+	Message string    `json:"message"`
+}
+
+// SaveMessage saves the message for the current branch and returns it.
+func SaveMessage(message string) (*SavedMessage, error) {
+	now := time.Now()
+	m := &SavedMessage{ID: newEntryID("messages", now), Branch: CurrentBranch(), Saved: now, Message: message}
+	return m, m.Save()
+}
+
+// Title returns the first line of the message.
+func (m *SavedMessage) Title() string {
+	title, _, _ := strings.Cut(m.Message, "\n")
+	return title
+}
+
+// Save stores the message in its file, in .git/goodcommit/messages.
+func (m *SavedMessage) Save() error {
+	dir, err := dataDir("messages")
+	if err != nil {
+		return err
+	}
+	return writeEntry(dir, m.ID, m)
+}
+
+// Drop removes the file of the message, e.g. once it is committed.
+func (m *SavedMessage) Drop() error {
+	dir, err := dataDir("messages")
+	if err != nil {
+		return err
+	}
+	return removeEntry(dir, m.ID)
+}
+
+// SavedMessages returns the saved messages of the repository, those of the current branch first
+// and then the most recent first.
+func SavedMessages() ([]*SavedMessage, error) {
+	dir, err := dataDir("messages")
+	if err != nil {
+		return nil, err
+	}
+	messages, err := readEntries[SavedMessage](dir)
+	if err != nil {
+		return nil, err
+	}
+	branch := CurrentBranch()
+	sort.Slice(messages, func(i, j int) bool {
+		if (messages[i].Branch == branch) != (messages[j].Branch == branch) {
+			return messages[i].Branch == branch
+		}
+		return messages[i].Saved.After(messages[j].Saved)
+	})
+	return messages, nil
+}
+
+// LoadSavedMessage returns the saved message with the given id.
+func LoadSavedMessage(id string) (*SavedMessage, error) {
+	messages, err := SavedMessages()
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range messages {
+		if m.ID == id {
+			return m, nil
+		}
+	}
+	return nil, fmt.Errorf("no saved message %q", id)
+}
@@ -1,29 +1,137 @@
 package goodcommit
 
-import "github.com/charmbracelet/huh"
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/charmbracelet/huh"
+)
 
 type Commit struct {
-	Type         string
-	Scope        string
-	Scopes       []string
-	Description  string
-	Body         string
-	Footer       string
-	Breaking     bool
-	CoAuthoredBy []string
-	Extras       map[string]*string
+	Type         string             `json:"type"`
+	Scope        string             `json:"scope"`
+	Scopes       []string           `json:"scopes"`
+	Description  string             `json:"description"`
+	Body         string             `json:"body"`
+	Footer       string             `json:"footer"` // Free text after the trailers, prefer Trailers.
+	Breaking     bool               `json:"breaking"`
+	CoAuthoredBy []string           `json:"coAuthoredBy"`
+	Trailers     []Trailer          `json:"trailers"` // The trailer block, in order, see AddTrailer.
+	Extras       map[string]*string `json:"extras"`
+	Amend        bool               `json:"amend"` // Set when the commit replaces HEAD, e.g. with goodcommit --amend.
+}
+
+// Clone returns a deep copy of the commit, whose extras don't share values with the original.
+func (c Commit) Clone() Commit {
+	clone := c
+	clone.Scopes = slices.Clone(c.Scopes)
+	clone.CoAuthoredBy = slices.Clone(c.CoAuthoredBy)
+	clone.Trailers = slices.Clone(c.Trailers)
+	clone.Extras = make(map[string]*string, len(c.Extras))
+	for key, value := range c.Extras {
+		if value != nil {
+			v := *value
+			clone.Extras[key] = &v
+		}
+	}
+	return clone
+}
+
+// Extra returns the value of the extra with the given key, or "" if the commit has none. It
+// saves checking for nil values, e.g. in message templates: {{.Extra "why"}}.
+func (c Commit) Extra(key string) string {
+	if v := c.Extras[key]; v != nil {
+		return *v
+	}
+	return ""
 }
 
 type ModuleConfig struct {
-	Page         int      `json:"page"`
-	Position     int      `json:"position"`
-	Name         string   `json:"name"`
+	Page     int    `json:"page"`
+	Position int    `json:"position"`
+	Name     string `json:"name"`
+	// Module is the registered module to use, when it is not the same as Name. It allows using
+	// a module twice, e.g. {"name": "team-scopes", "module": "scopes", "options": {...}}.
+	Module       string   `json:"module,omitempty"`
 	Active       bool     `json:"active"`
 	Path         string   `json:"path,omitempty"`
 	Priority     int      `json:"priority"`
 	Checkpoint   bool     `json:"checkpoint"`
 	Pinned       bool     `json:"pinned"`
-	Dependencies []string `json:"dependencies"`
+	Dependencies []string `json:"dependencies,omitempty"`
+	// When is an expression over the commit that tells if the field of the module is shown,
+	// e.g. `type in ["feat", "fix"] && !breaking`. See Condition for its syntax.
+	When string `json:"when,omitempty"`
+	// Exec is the command of an external module, a program in any language that speaks the
+	// protocol of the plugin package. Name is then free, as no built-in module is needed.
+	Exec string `json:"exec,omitempty"`
+	// Options are the module specific settings, e.g. the types of the types module, so they can
+	// be written in the configuration instead of in a separate file. See DecodeOptions.
+	Options json.RawMessage `json:"options,omitempty"`
+}
+
+// Type returns the name of the registered module of the configuration, Module or else Name.
+func (c ModuleConfig) Type() string {
+	if c.Module != "" {
+		return c.Module
+	}
+	return c.Name
+}
+
+// DecodeOptions decodes the options of the module into v, usually the module itself. Like the
+// configuration files, options are decoded strictly, so unknown keys are reported.
+// It does nothing when the module has no options.
+func (c ModuleConfig) DecodeOptions(v any) error {
+	if len(c.Options) == 0 {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(c.Options))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("error parsing options: %w", err)
+	}
+	return nil
+}
+
+// MergeOptions adds the items in the options of a module to the ones read from its file,
+// replacing those with the same id, so the options can override a shared file.
+func MergeOptions[T any](items, options []T, id func(T) string) []T {
+	for _, o := range options {
+		if j := slices.IndexFunc(items, func(i T) bool { return id(i) == id(o) }); j >= 0 {
+			items[j] = o
+		} else {
+			items = append(items, o)
+		}
+	}
+	return items
+}
+
+// CloseModules releases the resources held by the modules that implement io.Closer, e.g. the
+// process of an external module. It is called when goodcommit is done with the modules.
+func CloseModules(modules []Module) error {
+	var errs []error
+	for _, m := range modules {
+		if c, ok := m.(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				errs = append(errs, &ModuleError{Module: m.Name(), Err: err})
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// BindModules hands the loaded modules to those that implement Binder. It is called once the
+// modules of a configuration are loaded, and by the commiter with the modules it is given.
+func BindModules(modules []Module) {
+	for _, m := range modules {
+		if b, ok := m.(Binder); ok {
+			b.Bind(modules)
+		}
+	}
 }
 
 type Module interface {
@@ -36,3 +144,19 @@ type Module interface {
 	InitCommitInfo(commit *Commit) error
 	IsActive() bool
 }
+
+// Resetter is implemented by modules whose values in the commit depend on the answers given
+// before their field, e.g. the breaking flag that only feat and fix commits can set. The form
+// calls Reset whenever it builds the field of the module again: with shown false when the module
+// has no field for the commit, so it removes its values, and with shown true, so it removes the
+// values that are no longer among the options of its field.
+type Resetter interface {
+	Reset(commit *Commit, shown bool)
+}
+
+// Binder is implemented by modules that work together with other modules of the same loaded
+// set, e.g. the instances of a module configured more than once. Bind is called with the whole
+// set, replacing the one of an earlier call, see BindModules.
+type Binder interface {
+	Bind(modules []Module)
+}
@@ -0,0 +1,204 @@
+package goodcommit
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// headerRegexp matches a Conventional Commits header, e.g. "feat(api)!: add parser".
+var headerRegexp = regexp.MustCompile(`^([\w-]+)(?:\(([^()]*)\))?(!)?: (.*)$`)
+
+// trailerRegexp matches a git trailer line, e.g. "Signed-off-by: Alice <alice@example.com>".
+var trailerRegexp = regexp.MustCompile(`^([\w-]+|BREAKING CHANGE): (.*)$`)
+
+// Parse turns a commit message back into a Commit. It is the inverse of DefaultTemplate, so
+// rendering the parsed commit gives back the same message.
+//
+// The header is split into Type, Scope, Breaking and Description, and the trailers at the end
+// of the message are moved to Trailers, with the "Co-authored-by" values also in CoAuthoredBy.
+// The sections added to the body by the built-in modules are moved from the Body to the commit,
+// leaving the text the user wrote:
+//
+//   - "SCOPE:" and "SCOPES:" headers (scopes module) fill Scopes with the comma separated scope
+//     names, which are also stored in the "scopeNames" extra.
+//   - "WHY:" (why module) is stored in the "why" extra.
+//   - The emojis line (coauthors module) is stored in the "signature" extra.
+//
+// A "BREAKING CHANGE:" trailer, or paragraph as written by older versions, marks the commit as
+// breaking and is also stored in the "breakingmsg" extra.
+//
+// When there is no "SCOPE:" header, Scopes holds the comma separated values of the header scope.
+// A title prefix, e.g. the tickets written by the tickets module, is kept in the Description.
+func Parse(message string) (Commit, error) {
+	commit := Commit{Extras: make(map[string]*string)}
+
+	// Git stores messages ending with a newline, which would hide the trailers of the last line
+	message = strings.ReplaceAll(message, "\r\n", "\n")
+	message = strings.Trim(message, "\n")
+	header, rest, _ := strings.Cut(message, "\n")
+
+	match := headerRegexp.FindStringSubmatch(header)
+	if match == nil {
+		return commit, fmt.Errorf("header %q does not follow the format \"type(scope): description\"", header)
+	}
+	commit.Type = match[1]
+	commit.Scope = match[2]
+	commit.Breaking = match[3] == "!"
+	commit.Description = match[4]
+	for _, scope := range strings.Split(commit.Scope, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			commit.Scopes = append(commit.Scopes, scope)
+		}
+	}
+
+	// The body is separated from the header by a blank line, and from the trailers by another one.
+	rest = strings.TrimPrefix(rest, "\n")
+	body := rest
+	if i := strings.LastIndex(rest, "\n\n"); i >= 0 && isTrailerBlock(rest[i+2:]) {
+		body = rest[:i]
+		parseTrailers(&commit, rest[i+2:])
+	} else if !strings.Contains(rest, "\n\n") && isTrailerBlock(rest) {
+		body = ""
+		parseTrailers(&commit, rest)
+	}
+	commit.Body = body
+
+	parseBodySections(&commit)
+
+	return commit, nil
+}
+
+// RemoveSection removes the first occurrence of section from the body, along with the blank
+// lines that separated it from the rest of the body. Modules use it to turn a parsed body back
+// into the text the user wrote, before their PostProcess added the section again.
+func RemoveSection(body, section string) string {
+	i := strings.Index(body, section)
+	if section == "" || i < 0 {
+		return body
+	}
+	before := strings.TrimRight(body[:i], "\n")
+	after := strings.TrimLeft(body[i+len(section):], "\n")
+	if before != "" && after != "" {
+		return strings.TrimRightFunc(before+"\n\n"+after, unicode.IsSpace)
+	}
+	return strings.TrimRightFunc(before+after, unicode.IsSpace)
+}
+
+// parseTrailers moves the trailers in the block to the commit. The values of "Co-authored-by"
+// trailers are also added to CoAuthoredBy, and "BREAKING CHANGE" is stored in the "breakingmsg" extra.
+func parseTrailers(commit *Commit, block string) {
+	for _, line := range strings.Split(block, "\n") {
+		if isContinuation(line) {
+			t := &commit.Trailers[len(commit.Trailers)-1]
+			t.Value += "\n" + strings.TrimSpace(line)
+			continue
+		}
+		key, value, _ := strings.Cut(line, ": ")
+		commit.Trailers = append(commit.Trailers, Trailer{Key: key, Value: value})
+	}
+	for _, t := range commit.Trailers {
+		switch t.Key {
+		case "Co-authored-by":
+			commit.CoAuthoredBy = append(commit.CoAuthoredBy, t.Value)
+		case "BREAKING CHANGE", "BREAKING-CHANGE":
+			value := t.Value
+			commit.Breaking = true
+			commit.Extras["breakingmsg"] = &value
+		}
+	}
+}
+
+// parseBodySections moves the sections that the built-in modules add to the body to the commit.
+func parseBodySections(commit *Commit) {
+	paragraphs := strings.Split(commit.Body, "\n\n")
+	var kept []string
+	for i, p := range paragraphs {
+		if msg, ok := strings.CutPrefix(p, "BREAKING CHANGE: "); ok {
+			commit.Breaking = true
+			commit.Extras["breakingmsg"] = &msg
+			commit.Trailers = slices.Insert(commit.Trailers, 0, Trailer{Key: "BREAKING CHANGE", Value: msg})
+			continue
+		}
+		if i == len(paragraphs)-1 && isSignature(p) {
+			signature := strings.TrimSpace(p)
+			commit.Extras["signature"] = &signature
+			continue
+		}
+		var lines []string
+		for _, line := range strings.Split(p, "\n") {
+			if why, ok := strings.CutPrefix(line, "WHY: "); ok {
+				commit.Extras["why"] = &why
+			} else if names, ok := cutScopeHeader(line); ok {
+				commit.Scopes = nil
+				for _, name := range strings.Split(names, ",") {
+					if name = strings.TrimSpace(name); name != "" {
+						commit.Scopes = append(commit.Scopes, name)
+					}
+				}
+				names = strings.Join(commit.Scopes, ScopeNamesSeparator)
+				commit.Extras["scopeNames"] = &names
+			} else {
+				lines = append(lines, line)
+			}
+		}
+		if len(lines) > 0 {
+			kept = append(kept, strings.Join(lines, "\n"))
+		}
+	}
+	commit.Body = strings.Join(kept, "\n\n")
+}
+
+// ScopeNamesSeparator separates the scope names of the "SCOPES:" header, as names can have spaces.
+const ScopeNamesSeparator = ", "
+
+// cutScopeHeader returns the scope names of a "SCOPE:" or "SCOPES:" line.
+func cutScopeHeader(line string) (string, bool) {
+	if names, ok := strings.CutPrefix(line, "SCOPES: "); ok {
+		return names, true
+	}
+	return strings.CutPrefix(line, "SCOPE: ")
+}
+
+// isTrailerBlock reports whether every line of the paragraph is a git trailer.
+func isTrailerBlock(paragraph string) bool {
+	if paragraph == "" {
+		return false
+	}
+	for i, line := range strings.Split(paragraph, "\n") {
+		if i > 0 && isContinuation(line) {
+			continue
+		}
+		match := trailerRegexp.FindStringSubmatch(line)
+		if match == nil || isBodySection(match[1]) {
+			return false
+		}
+	}
+	return true
+}
+
+// isContinuation reports whether the line continues the value of the trailer before it.
+func isContinuation(line string) bool {
+	return strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")
+}
+
+// isBodySection reports whether the key is one of the headers that modules add to the body,
+// which look like trailers but are part of the body.
+func isBodySection(key string) bool {
+	return key == "WHY" || key == "SCOPE" || key == "SCOPES"
+}
+
+// isSignature reports whether the paragraph is a line of emojis, as added by the coauthors module.
+func isSignature(paragraph string) bool {
+	if strings.TrimSpace(paragraph) == "" || strings.Contains(paragraph, "\n") {
+		return false
+	}
+	for _, r := range paragraph {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsPunct(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,212 @@
+package goodcommit
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// sections are what the built-in modules add to a commit, combined in every way by
+// TestParseRoundTrip.
+var sections = []struct {
+	name  string
+	apply func(c *Commit)
+}{
+	{"scope", func(c *Commit) {
+		c.Scope, c.Scopes = "🔫", []string{"Goodcommit"}
+		c.Extras["scopeNames"] = str("Goodcommit")
+	}},
+	{"scopes", func(c *Commit) {
+		c.Scope, c.Scopes = "🔫📦", []string{"Goodcommit", "Modules"}
+		c.Extras["scopeNames"] = str("Goodcommit, Modules")
+	}},
+	{"why", func(c *Commit) {
+		c.Extras["why"] = str("Because it was slow.")
+	}},
+	{"body", func(c *Commit) {
+		c.Body = "The form crashed when a type had no scopes.\n\nNow it shows an error."
+	}},
+	{"signature", func(c *Commit) {
+		c.Extras["signature"] = str("🫏 🦊")
+	}},
+	{"breaking", func(c *Commit) {
+		c.Breaking = true
+		c.Extras["breakingmsg"] = str("The v1 API is gone.")
+		c.Trailers = append(c.Trailers, Trailer{Key: "BREAKING CHANGE", Value: "The v1 API is gone."})
+	}},
+	{"coauthors", func(c *Commit) {
+		c.CoAuthoredBy = []string{"Alice <alice@example.com>", "Bob <bob@example.com>"}
+		for _, a := range c.CoAuthoredBy {
+			c.Trailers = append(c.Trailers, Trailer{Key: "Co-authored-by", Value: a})
+		}
+	}},
+	{"signoff", func(c *Commit) {
+		c.Trailers = append(c.Trailers, Trailer{Key: "Signed-off-by", Value: "Carol <carol@example.com>"})
+	}},
+}
+
+// TestParseRoundTrip renders commits with every combination of sections through the default
+// template, and checks that Parse gives back the same commit, also when git adds a final newline.
+func TestParseRoundTrip(t *testing.T) {
+	for mask := 0; mask < 1<<len(sections); mask++ {
+		if mask&3 == 3 {
+			continue // A commit has either one scope or several
+		}
+		want := Commit{Type: "feat", Description: "Add parser", Extras: make(map[string]*string)}
+		var names []string
+		for i, s := range sections {
+			if mask&(1<<i) == 0 {
+				continue
+			}
+			s.apply(&want)
+			names = append(names, s.name)
+		}
+		if len(names) == 0 {
+			names = []string{"header"}
+		}
+
+		t.Run(strings.Join(names, "+"), func(t *testing.T) {
+			message, err := DefaultTemplate().Render(want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, m := range []string{message, message + "\n"} {
+				got, err := Parse(m)
+				if err != nil {
+					t.Fatal(err)
+				}
+				assertCommit(t, m, got, want)
+
+				again, err := DefaultTemplate().Render(got)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if again != message {
+					t.Errorf("rendering the parsed commit gives\n%s\nwant:\n%s", again, message)
+				}
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    Commit
+	}{
+		{
+			name:    "trailers after a final newline",
+			message: "feat: x\n\nbody\n\nSigned-off-by: A <a@b>\n",
+			want: Commit{
+				Type:        "feat",
+				Description: "x",
+				Body:        "body",
+				Trailers:    []Trailer{{Key: "Signed-off-by", Value: "A <a@b>"}},
+			},
+		},
+		{
+			name:    "only trailers",
+			message: "fix: y\n\nRefs: ABC-1\nSigned-off-by: A <a@b>\n\n",
+			want: Commit{
+				Type:        "fix",
+				Description: "y",
+				Trailers:    []Trailer{{Key: "Refs", Value: "ABC-1"}, {Key: "Signed-off-by", Value: "A <a@b>"}},
+			},
+		},
+		{
+			name:    "crlf",
+			message: "fix(api,cli)!: y\r\n\r\nbody\r\n\r\nCo-authored-by: A <a@b>\r\n",
+			want: Commit{
+				Type:         "fix",
+				Scope:        "api,cli",
+				Scopes:       []string{"api", "cli"},
+				Breaking:     true,
+				Description:  "y",
+				Body:         "body",
+				CoAuthoredBy: []string{"A <a@b>"},
+				Trailers:     []Trailer{{Key: "Co-authored-by", Value: "A <a@b>"}},
+			},
+		},
+		{
+			name:    "breaking change paragraph",
+			message: "feat: z\n\nBody.\n\nBREAKING CHANGE: Gone.\n\nSigned-off-by: A <a@b>\n",
+			want: Commit{
+				Type:        "feat",
+				Breaking:    true,
+				Description: "z",
+				Body:        "Body.",
+				Extras:      map[string]*string{"breakingmsg": str("Gone.")},
+				Trailers:    []Trailer{{Key: "BREAKING CHANGE", Value: "Gone."}, {Key: "Signed-off-by", Value: "A <a@b>"}},
+			},
+		},
+		{
+			name:    "scope names with spaces",
+			message: "feat(🏗️💻): x\n\nSCOPES: Build System, Command Line\n",
+			want: Commit{
+				Type:        "feat",
+				Scope:       "🏗️💻",
+				Scopes:      []string{"Build System", "Command Line"},
+				Description: "x",
+				Extras:      map[string]*string{"scopeNames": str("Build System, Command Line")},
+			},
+		},
+		{
+			name:    "trailer-like body",
+			message: "docs: w\n\nNote: this is not a trailer.\nIt is the body.\n",
+			want: Commit{
+				Type:        "docs",
+				Description: "w",
+				Body:        "Note: this is not a trailer.\nIt is the body.",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.message)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertCommit(t, tt.message, got, tt.want)
+		})
+	}
+}
+
+func TestParseInvalidHeader(t *testing.T) {
+	if _, err := Parse("Add parser\n\nBody."); err == nil {
+		t.Error("expected an error for a header without a type")
+	}
+}
+
+// assertCommit compares the fields of the parsed commit that the default template renders.
+func assertCommit(t *testing.T, message string, got, want Commit) {
+	t.Helper()
+	if got.Type != want.Type || got.Scope != want.Scope || got.Breaking != want.Breaking ||
+		got.Description != want.Description || got.Body != want.Body {
+		t.Errorf("Parse(%q) header and body = %q %q %v %q %q, want %q %q %v %q %q", message,
+			got.Type, got.Scope, got.Breaking, got.Description, got.Body,
+			want.Type, want.Scope, want.Breaking, want.Description, want.Body)
+	}
+	if !slices.Equal(got.Scopes, want.Scopes) {
+		t.Errorf("Parse(%q).Scopes = %q, want %q", message, got.Scopes, want.Scopes)
+	}
+	if !slices.Equal(got.CoAuthoredBy, want.CoAuthoredBy) {
+		t.Errorf("Parse(%q).CoAuthoredBy = %q, want %q", message, got.CoAuthoredBy, want.CoAuthoredBy)
+	}
+	if !reflect.DeepEqual(got.Trailers, want.Trailers) {
+		t.Errorf("Parse(%q).Trailers = %q, want %q", message, got.Trailers, want.Trailers)
+	}
+	extras := func(c Commit) map[string]string {
+		m := make(map[string]string)
+		for k, v := range c.Extras {
+			if v != nil {
+				m[k] = *v
+			}
+		}
+		return m
+	}
+	if g, w := extras(got), extras(want); !reflect.DeepEqual(g, w) {
+		t.Errorf("Parse(%q).Extras = %q, want %q", message, g, w)
+	}
+}
@@ -0,0 +1,142 @@
+package plugin
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	gc "github.com/nantli/goodcommit"
+)
+
+// Kinds of fields an external module can ask for.
+const (
+	KindInput       = "input"
+	KindText        = "text"
+	KindSelect      = "select"
+	KindMultiSelect = "multiselect"
+	KindConfirm     = "confirm"
+	KindNote        = "note"
+)
+
+// Field is the declarative spec of the field returned by an external module for newField.
+// The value of the field is stored in the commit extras under Key: the text of an input, text
+// or select, the comma separated values of a multiselect, and "true" or "false" for a confirm.
+// A note only shows its title and description.
+type Field struct {
+	Kind        string   `json:"kind"`
+	Key         string   `json:"key,omitempty"`
+	Title       string   `json:"title,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Placeholder string   `json:"placeholder,omitempty"` // For input and text.
+	CharLimit   int      `json:"charLimit,omitempty"`   // For input and text, 0 means no limit.
+	Options     []Option `json:"options,omitempty"`     // For select and multiselect.
+	Limit       int      `json:"limit,omitempty"`       // Maximum selected options of a multiselect, 0 means no limit.
+	Affirmative string   `json:"affirmative,omitempty"` // For confirm, "Yes" by default.
+	Negative    string   `json:"negative,omitempty"`    // For confirm, "No" by default.
+}
+
+// Option is an option of a select or multiselect field.
+type Option struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// fieldValues holds the values that huh can't store in the commit extras directly.
+type fieldValues struct {
+	selected  []string
+	confirmed bool
+}
+
+// build returns the huh field of the spec, bound to the commit extras. The current value of
+// the extra, if any, is used as the initial value of the field.
+func (f *Field) build(commit *gc.Commit, values *fieldValues) (huh.Field, error) {
+	if f.Kind != KindNote && f.Key == "" {
+		return nil, fmt.Errorf("%s field without key", f.Kind)
+	}
+	if commit.Extras == nil {
+		commit.Extras = make(map[string]*string)
+	}
+	value := commit.Extras[f.Key]
+	if value == nil && f.Kind != KindNote {
+		value = new(string)
+		commit.Extras[f.Key] = value
+	}
+
+	var options []huh.Option[string]
+	for _, o := range f.Options {
+		options = append(options, huh.NewOption(o.Label, o.Value))
+	}
+
+	switch f.Kind {
+	case KindInput:
+		return huh.NewInput().
+			Title(f.Title).
+			Description(f.Description).
+			Placeholder(f.Placeholder).
+			CharLimit(f.CharLimit).
+			Value(value), nil
+	case KindText:
+		field := huh.NewText().
+			Title(f.Title).
+			Description(f.Description).
+			Placeholder(f.Placeholder)
+		if f.CharLimit > 0 {
+			field.CharLimit(f.CharLimit)
+		}
+		return field.Value(value), nil
+	case KindSelect:
+		return huh.NewSelect[string]().
+			Title(f.Title).
+			Description(f.Description).
+			Options(options...).
+			Value(value), nil
+	case KindMultiSelect:
+		values.selected = nil
+		if *value != "" {
+			values.selected = strings.Split(*value, ",")
+		}
+		return huh.NewMultiSelect[string]().
+			Title(f.Title).
+			Description(f.Description).
+			Options(options...).
+			Limit(f.Limit).
+			Value(&values.selected), nil
+	case KindConfirm:
+		values.confirmed, _ = strconv.ParseBool(*value)
+		field := huh.NewConfirm().
+			Title(f.Title).
+			Description(f.Description)
+		if f.Affirmative != "" {
+			field.Affirmative(f.Affirmative)
+		}
+		if f.Negative != "" {
+			field.Negative(f.Negative)
+		}
+		return field.Value(&values.confirmed), nil
+	case KindNote:
+		return huh.NewNote().
+			Title(f.Title).
+			Description(f.Description), nil
+	default:
+		return nil, fmt.Errorf("unknown field kind %q", f.Kind)
+	}
+}
+
+// store copies the values that huh could not store directly to the commit extras.
+func (f *Field) store(commit *gc.Commit, values *fieldValues) {
+	var value string
+	switch f.Kind {
+	case KindMultiSelect:
+		value = strings.Join(values.selected, ",")
+	case KindConfirm:
+		value = strconv.FormatBool(values.confirmed)
+	default:
+		return
+	}
+	if commit.Extras[f.Key] != nil {
+		*commit.Extras[f.Key] = value
+	} else {
+		commit.Extras[f.Key] = &value
+	}
+}
@@ -0,0 +1,270 @@
+// Package plugin provides external goodcommit modules, programs written in any language that
+// are configured with the command to run in "exec":
+//
+//	{
+//	    "name": "ticket",
+//	    "active": true,
+//	    "page": 2,
+//	    "position": 1,
+//	    "exec": "./scripts/ticket-module",
+//	    "options": { "project": "GC" }
+//	}
+//
+// The repository configuration files can only set exec with gc.AllowRepoExec, and the lint and
+// config validate commands never start external modules.
+// The program is started once and kept running. goodcommit writes a JSON request per line to
+// its stdin, and reads a JSON response per line from its stdout. Its stderr is shown to the user.
+// The requests mirror the methods of gc.Module:
+//
+//	{"method": "loadConfig", "config": {...}}
+//	{"method": "initCommitInfo", "commit": {...}}
+//	{"method": "newField", "commit": {...}}
+//	{"method": "postProcess", "commit": {...}}
+//
+// newField is sent again whenever the page of the field is entered after the commit changed.
+// Every request must be answered within 10 seconds, or the module is stopped, with a Response, e.g. {"commit": {...}} for initCommitInfo and
+// postProcess, {"field": {...}} or {} for newField, and {"error": "..."} to report a problem.
+// The commit has the JSON fields of gc.Commit, and the values of the fields are stored in its
+// extras. See examples/plugins for a reference module and "goodcommit plugin test" to run one.
+package plugin
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/charmbracelet/huh"
+	gc "github.com/nantli/goodcommit"
+)
+
+// Methods of the requests sent to the external module.
+const (
+	MethodLoadConfig     = "loadConfig"
+	MethodInitCommitInfo = "initCommitInfo"
+	MethodNewField       = "newField"
+	MethodPostProcess    = "postProcess"
+)
+
+// Request is sent to the external module, one per line.
+type Request struct {
+	Method string           `json:"method"`
+	Config *gc.ModuleConfig `json:"config,omitempty"`
+	Commit *gc.Commit       `json:"commit,omitempty"`
+}
+
+// Response is the answer of the external module to a request, one per line.
+type Response struct {
+	Commit *gc.Commit `json:"commit,omitempty"` // The updated commit, it is kept as is if missing.
+	Field  *Field     `json:"field,omitempty"`  // The field to show, none if missing.
+	Error  string     `json:"error,omitempty"`
+}
+
+// responseTimeout is how long the external module has to answer a request.
+var responseTimeout = 10 * time.Second
+
+var errTimeout = errors.New("timeout")
+
+type module struct {
+	config gc.ModuleConfig
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+	field  *Field
+	values fieldValues
+	log    io.Writer // Where requests and responses are written, if set.
+}
+
+// call sends the request to the external module and returns its response. The module is
+// started on the first call.
+func (m *module) call(req Request) (Response, error) {
+	var resp Response
+	if err := m.start(); err != nil {
+		return resp, err
+	}
+
+	line, err := json.Marshal(req)
+	if err != nil {
+		return resp, err
+	}
+	if m.log != nil {
+		fmt.Fprintf(m.log, "> %s\n", line)
+	}
+	if _, err := m.stdin.Write(append(line, '\n')); err != nil {
+		return resp, fmt.Errorf("error sending %s request to %s: %w", req.Method, m.config.Exec, err)
+	}
+	line, err = m.read()
+	if errors.Is(err, errTimeout) {
+		return resp, fmt.Errorf("no %s response from %s after %s", req.Method, m.config.Exec, responseTimeout)
+	}
+	if err != nil {
+		return resp, fmt.Errorf("error reading %s response from %s: %w", req.Method, m.config.Exec, err)
+	}
+	if m.log != nil {
+		fmt.Fprintf(m.log, "< %s", line)
+	}
+	if err := json.Unmarshal(line, &resp); err != nil {
+		return resp, fmt.Errorf("invalid %s response from %s: %w", req.Method, m.config.Exec, err)
+	}
+	if resp.Error != "" {
+		return resp, errors.New(resp.Error)
+	}
+	return resp, nil
+}
+
+// read returns the next line written by the external module. If none comes within
+// responseTimeout, the module is stopped, it is stuck or not speaking the protocol.
+func (m *module) read() ([]byte, error) {
+	type result struct {
+		line []byte
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		line, err := m.stdout.ReadBytes('\n')
+		done <- result{line, err}
+	}()
+	select {
+	case r := <-done:
+		return r.line, r.err
+	case <-time.After(responseTimeout):
+		m.cmd.Process.Kill()
+		return nil, errTimeout
+	}
+}
+
+// start runs the command of the external module, if it is not running yet.
+func (m *module) start() error {
+	if m.cmd != nil {
+		return nil
+	}
+	cmd := exec.Command(m.config.Exec)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("error starting %s: %w", m.config.Exec, err)
+	}
+	m.cmd, m.stdin, m.stdout = cmd, stdin, bufio.NewReader(stdout)
+	return nil
+}
+
+// Close closes the stdin of the external module and waits for it to exit. gc.CloseModules
+// calls it when goodcommit is done with the modules.
+func (m *module) Close() error {
+	if m.cmd == nil {
+		return nil
+	}
+	m.stdin.Close()
+	err := m.cmd.Wait()
+	m.cmd = nil
+	return err
+}
+
+// LoadConfig sends the module configuration, including its options.
+func (m *module) LoadConfig() error {
+	_, err := m.call(Request{Method: MethodLoadConfig, Config: &m.config})
+	return err
+}
+
+// NewField asks the external module for the field to show, built from its Field spec.
+// It is asked again whenever the page of the field is entered after the commit changed, so
+// the values of the previous field are stored first.
+func (m *module) NewField(commit *gc.Commit) (huh.Field, error) {
+	if m.field != nil {
+		m.field.store(commit, &m.values)
+	}
+	resp, err := m.call(Request{Method: MethodNewField, Commit: commit})
+	if err != nil || resp.Field == nil {
+		return nil, err
+	}
+	m.field = resp.Field
+	return m.field.build(commit, &m.values)
+}
+
+// PostProcess sends the commit with the values of the field to the external module, and keeps
+// the commit it returns.
+func (m *module) PostProcess(commit *gc.Commit) error {
+	if m.field != nil {
+		m.field.store(commit, &m.values)
+	}
+	resp, err := m.call(Request{Method: MethodPostProcess, Commit: commit})
+	if err != nil {
+		return err
+	}
+	update(commit, resp.Commit)
+	return nil
+}
+
+func (m *module) Config() gc.ModuleConfig {
+	return m.config
+}
+
+func (m *module) SetConfig(config gc.ModuleConfig) {
+	m.config = config
+}
+
+// Name returns the name of the module in the configuration.
+func (m *module) Name() string {
+	return m.config.Name
+}
+
+// InitCommitInfo sends the initial commit, e.g. the one being amended, to the external module
+// and keeps the commit it returns.
+func (m *module) InitCommitInfo(commit *gc.Commit) error {
+	resp, err := m.call(Request{Method: MethodInitCommitInfo, Commit: commit})
+	if err != nil {
+		return err
+	}
+	update(commit, resp.Commit)
+	return nil
+}
+
+func (m *module) IsActive() bool {
+	return m.config.Active
+}
+
+// update replaces the commit with the one returned by the external module. The extras are
+// updated in place, as the fields of other modules may point to them.
+func update(commit *gc.Commit, updated *gc.Commit) {
+	if updated == nil {
+		return
+	}
+	extras := commit.Extras
+	if extras == nil {
+		extras = make(map[string]*string)
+	}
+	*commit = *updated
+	for key, value := range updated.Extras {
+		switch {
+		case value == nil:
+			delete(extras, key)
+		case extras[key] != nil:
+			*extras[key] = *value
+		default:
+			extras[key] = value
+		}
+	}
+	commit.Extras = extras
+}
+
+// New returns an external module for the configuration, running the command in its Exec field.
+// It is registered in init, so importing this package creates a module for every configuration
+// entry with an exec command.
+func New(config gc.ModuleConfig) gc.Module {
+	return &module{config: config}
+}
+
+func init() {
+	gc.RegisterExecModules(New)
+}
@@ -0,0 +1,86 @@
+package plugin
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gc "github.com/nantli/goodcommit"
+)
+
+// ticketConfig is the configuration of the reference module of examples/plugins.
+func ticketConfig(t *testing.T) gc.ModuleConfig {
+	t.Helper()
+	if _, err := exec.LookPath("python3"); err != nil {
+		t.Skip("python3 is needed to run the reference module")
+	}
+	return gc.ModuleConfig{Name: "ticket", Active: true, Exec: "../examples/plugins/ticket.py", Options: json.RawMessage(`{"project": "GC"}`)}
+}
+
+// TestExamplePlugin runs the reference module the way "goodcommit plugin test" does.
+func TestExamplePlugin(t *testing.T) {
+	tests := []struct {
+		name   string
+		commit gc.Commit
+		values map[string]string
+		footer string
+		err    string
+	}{
+		{"ticket number", gc.Commit{Type: "feat", Description: "add thing"}, map[string]string{"ticket": "42"}, "\nRefs: GC-42", ""},
+		{"ticket id", gc.Commit{Type: "feat", Description: "add thing"}, map[string]string{"ticket": "abc-7"}, "\nRefs: ABC-7", ""},
+		{"no ticket", gc.Commit{Type: "feat", Description: "add thing"}, nil, "", ""},
+		{"amend", gc.Commit{Type: "feat", Description: "add thing", Footer: "\nRefs: GC-9", Amend: true}, nil, "\nRefs: GC-9", ""},
+		{"invalid ticket", gc.Commit{Type: "feat", Description: "add thing"}, map[string]string{"ticket": "not a ticket"}, "", "invalid ticket"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var log strings.Builder
+			err := Run(ticketConfig(t), &tt.commit, tt.values, &log)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("Run() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Run() error = %v\n%s", err, log.String())
+			}
+			if tt.commit.Footer != tt.footer {
+				t.Errorf("footer = %q, want %q", tt.commit.Footer, tt.footer)
+			}
+			for _, method := range []string{MethodLoadConfig, MethodInitCommitInfo, MethodNewField, MethodPostProcess} {
+				if !strings.Contains(log.String(), `"method":"`+method+`"`) {
+					t.Errorf("no %s request in the log:\n%s", method, log.String())
+				}
+			}
+		})
+	}
+}
+
+// TestResponseTimeout checks that a module that never answers is stopped instead of blocking.
+func TestResponseTimeout(t *testing.T) {
+	defer func(timeout time.Duration) { responseTimeout = timeout }(responseTimeout)
+	responseTimeout = 100 * time.Millisecond
+
+	script := filepath.Join(t.TempDir(), "silent")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nexec sleep 60\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- Run(gc.ModuleConfig{Name: "silent", Active: true, Exec: script}, &gc.Commit{}, nil, io.Discard)
+	}()
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "no loadConfig response") {
+			t.Errorf("Run() error = %v, want a timeout", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() is still waiting for the module")
+	}
+}
@@ -0,0 +1,46 @@
+package plugin
+
+import (
+	"fmt"
+	"io"
+
+	gc "github.com/nantli/goodcommit"
+)
+
+// Run runs an external module the way goodcommit does, but without a form, to test it locally.
+// It loads the configuration, sends the commit to initCommitInfo, asks for the field and
+// post-processes the commit with the given values set in its extras, as if the user had
+// entered them. Every request and response is written to log.
+func Run(config gc.ModuleConfig, commit *gc.Commit, values map[string]string, log io.Writer) error {
+	m := &module{config: config, log: log}
+	defer m.Close()
+
+	if commit.Extras == nil {
+		commit.Extras = make(map[string]*string)
+	}
+	if err := m.LoadConfig(); err != nil {
+		return fmt.Errorf("loadConfig: %w", err)
+	}
+	if err := m.InitCommitInfo(commit); err != nil {
+		return fmt.Errorf("initCommitInfo: %w", err)
+	}
+
+	for key, value := range values {
+		value := value
+		commit.Extras[key] = &value
+	}
+	field, err := m.NewField(commit)
+	if err != nil {
+		return fmt.Errorf("newField: %w", err)
+	}
+	if field == nil {
+		fmt.Fprintln(log, "# no field")
+	} else if _, ok := values[m.field.Key]; !ok && m.field.Kind != KindNote {
+		fmt.Fprintf(log, "# no value given for %q, use --set %s=value\n", m.field.Key, m.field.Key)
+	}
+
+	if err := m.PostProcess(commit); err != nil {
+		return fmt.Errorf("postProcess: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package goodcommit
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Factory returns a new instance of a module, with its default configuration.
+type Factory func() Module
+
+// registry holds the factories of the registered modules by name.
+var registry = make(map[string]Factory)
+
+// execModuleFactory creates the modules of the configuration entries with an exec command.
+var execModuleFactory func(config ModuleConfig) Module
+
+// Register makes a module available to the configuration under the given name. The built-in
+// modules register themselves in init, so importing the package of a module is enough to use it
+// in a custom goodcommit binary. It panics if the name is already registered.
+func Register(name string, factory Factory) {
+	if _, ok := registry[name]; ok {
+		panic(fmt.Sprintf("goodcommit: module %s registered twice", name))
+	}
+	registry[name] = factory
+}
+
+// Registered returns the names of the registered modules, sorted.
+func Registered() []string {
+	var names []string
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewRegistered returns a new instance of the module registered under name, or nil if there is none.
+func NewRegistered(name string) Module {
+	if factory := registry[name]; factory != nil {
+		return factory()
+	}
+	return nil
+}
+
+// RegisterExecModules sets the factory of the external modules, the configuration entries with
+// an exec command. The plugin package registers itself in init.
+func RegisterExecModules(factory func(config ModuleConfig) Module) {
+	execModuleFactory = factory
+}
+
+// NewModule returns a new instance of the module of a configuration entry: an external module
+// if the entry has an exec command, otherwise the registered module of its Type. The module is
+// given the entry as its configuration, but LoadConfig is not called.
+func NewModule(config ModuleConfig) (Module, error) {
+	var m Module
+	switch {
+	case config.Exec != "" && execModuleFactory == nil:
+		return nil, &ModuleError{Module: config.Name, Err: errors.New("external modules are not available in this build")}
+	case config.Exec != "":
+		m = execModuleFactory(config)
+	default:
+		if m = NewRegistered(config.Type()); m == nil {
+			return nil, &UnknownModuleError{Name: config.Type()}
+		}
+	}
+	m.SetConfig(config)
+	return m, nil
+}
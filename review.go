@@ -0,0 +1,69 @@
+package goodcommit
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// ReviewAction is what the user chose to do with the message in the review step.
+type ReviewAction int
+
+const (
+	// ReviewCommit commits the message as shown.
+	ReviewCommit ReviewAction = iota
+	// ReviewEditField asks again for the value of a module, the one in ReviewChoice.Module.
+	ReviewEditField
+	// ReviewEditMessage opens the message in the editor of the user.
+	ReviewEditMessage
+	// ReviewCopy copies the message to the clipboard.
+	ReviewCopy
+	// ReviewAbort stops without committing, keeping the message as a draft.
+	ReviewAbort
+)
+
+// ReviewChoice is returned by Commiter.Review.
+type ReviewChoice struct {
+	Action ReviewAction
+	Module string // Name of the module to edit, for ReviewEditField.
+}
+
+// ErrAborted is returned by Execute, along with the message, when the user aborts in the review step.
+var ErrAborted = errors.New("commit aborted")
+
+// Editor returns the editor of the user, $EDITOR or vim if it is not set.
+func Editor() string {
+	if editor := os.Getenv("EDITOR"); editor != "" {
+		return editor
+	}
+	return "vim"
+}
+
+// EditMessage opens the message in the editor of the user and returns it as saved.
+func EditMessage(message string) (string, error) {
+	f, err := os.CreateTemp("", "goodcommit-msg-*.txt")
+	if err != nil {
+		return "", fmt.Errorf("error creating message file: %w", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(message); err != nil {
+		f.Close()
+		return "", fmt.Errorf("error writing message file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("error writing message file: %w", err)
+	}
+
+	cmd := exec.Command(Editor(), f.Name())
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("error opening editor: %w", err)
+	}
+
+	edited, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", fmt.Errorf("error reading message file: %w", err)
+	}
+	return string(edited), nil
+}
@@ -0,0 +1,76 @@
+package goodcommit
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// SchemaDraft is the JSON Schema version of the generated schemas.
+const SchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema document.
+type Schema map[string]any
+
+// SchemaModule is implemented by modules that read a JSON configuration file from the path of
+// their ModuleConfig, returning the schema of that file.
+type SchemaModule interface {
+	Schema() Schema
+}
+
+// ConfigSchema returns the JSON Schema of the configuration file, generated from Config and ModuleConfig.
+func ConfigSchema() Schema {
+	return NewSchema("goodcommit configuration", Config{})
+}
+
+// NewSchema returns the JSON Schema of the type of v, generated from its exported fields and
+// their json tags. Objects don't allow other properties, as configuration files are decoded strictly.
+func NewSchema(title string, v any) Schema {
+	s := schemaOf(reflect.TypeOf(v))
+	s["$schema"] = SchemaDraft
+	s["title"] = title
+	return s
+}
+
+// schemaOf returns the schema of a value of type t.
+func schemaOf(t reflect.Type) Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == reflect.TypeOf(json.RawMessage{}) {
+		// Any value, e.g. the options of a module
+		return Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return Schema{"type": "array", "items": schemaOf(t.Elem())}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": schemaOf(t.Elem())}
+	case reflect.Struct:
+		properties := Schema{}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if !f.IsExported() || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			properties[name] = schemaOf(f.Type)
+		}
+		return Schema{"type": "object", "properties": properties, "additionalProperties": false}
+	default:
+		return Schema{}
+	}
+}
@@ -0,0 +1,28 @@
+package scopes
+
+import (
+	"hash/fnv"
+	"slices"
+)
+
+// Emojis are given to the scopes that are not written by hand: the discovered ones and those
+// created by "goodcommit init --scopes-from-dirs". Scopes are told apart by their emoji in the
+// commit title, so no two scopes should have the same one.
+var Emojis = []string{"📦", "🧩", "🔧", "🎨", "🔌", "📐", "🧪", "📚", "🗂️", "🛠️", "🧭", "🪄", "🧱", "🔭", "🎯", "🧬",
+	"🚀", "🛰️", "🌿", "🔑", "🗃️", "📡", "🧰", "🪁", "🐙", "🦀", "🐹", "🦊", "🐝", "🌀", "💠", "🔷"}
+
+// PickEmoji returns an emoji of Emojis that is not in used for the scope with the given id. It is
+// derived from the id, so a scope usually keeps its emoji when others are added or removed.
+// Once every emoji is used it returns "", the scope then has no emoji and is shown by its id in
+// the commit title, see ScopeSeparator.
+func PickEmoji(id string, used []string) string {
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	start := int(h.Sum32() % uint32(len(Emojis)))
+	for k := range Emojis {
+		if e := Emojis[(start+k)%len(Emojis)]; !slices.Contains(used, e) {
+			return e
+		}
+	}
+	return ""
+}
@@ -0,0 +1,25 @@
+package scopes
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPickEmoji(t *testing.T) {
+	first := PickEmoji("services/billing", nil)
+	if !slices.Contains(Emojis, first) {
+		t.Fatalf("PickEmoji() = %q, want one of Emojis", first)
+	}
+	if got := PickEmoji("services/billing", nil); got != first {
+		t.Errorf("PickEmoji() = %q, then %q for the same id", first, got)
+	}
+	if got := PickEmoji("services/billing", []string{first}); got == first || got == "" {
+		t.Errorf("PickEmoji() = %q with %q used", got, first)
+	}
+	if got := PickEmoji("services/billing", Emojis[:len(Emojis)-1]); got != Emojis[len(Emojis)-1] {
+		t.Errorf("PickEmoji() = %q, want the only unused emoji %q", got, Emojis[len(Emojis)-1])
+	}
+	if got := PickEmoji("services/billing", Emojis); got != "" {
+		t.Errorf("PickEmoji() = %q with every emoji used, want none", got)
+	}
+}
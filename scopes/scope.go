@@ -1,13 +1,12 @@
 // Package scopes provides a github.com/nantli/goodcommit module that allows the user to select scopes for the commit.
 // It presents a multi-select menu with the available scopes.
-// The selected scopes are then added to the commit title and body.
+// The selected scopes are then added to the commit title and, through the message template, to the body.
 package scopes
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
 	"slices"
+	"strings"
 
 	"github.com/charmbracelet/huh"
 	gc "github.com/nantli/goodcommit"
@@ -20,15 +19,52 @@ type item struct {
 	Title       string   `json:"title"`
 	Description string   `json:"description"`
 	Emoji       string   `json:"emoji"`
-	Conditional []string `json:"conditional"` // The types of commits that this scope is valid for.
+	Conditional []string `json:"conditional"` // The types of commits that this scope is valid for, "*" for all.
+	Paths       []string `json:"paths"`       // Globs of the files of the scope, e.g. "cmd/**", to suggest it.
+
+	dir string // Directory of the package the scope was discovered from.
+}
+
+// mark returns how the scope is shown in the commit title, its emoji or else its id.
+func (i item) mark() string {
+	if i.Emoji != "" {
+		return i.Emoji
+	}
+	return i.Id
+}
+
+// option returns the option of the scope in the field, as it would be written for the given type.
+func (i item) option(commitType string) huh.Option[string] {
+	return huh.NewOption(commitType+"("+i.mark()+"): "+i.Name+" - "+i.Description, i.Id)
+}
+
+// validFor tells if the scope is valid for the given commit type.
+func (i item) validFor(commitType string) bool {
+	return slices.Contains(i.Conditional, commitType) || slices.Contains(i.Conditional, "*")
 }
 
 // MODULE_NAME is the name of the module and should be used as the name of the module in the config.json file.
 const MODULE_NAME = "scopes"
 
+// ScopeSeparator separates the scopes in the commit title when any of them has no emoji and is
+// shown by its id, e.g. "feat(📦,apigw)". The emojis of the scopes are otherwise written together.
+const ScopeSeparator = ","
+
 type scopes struct {
 	config gc.ModuleConfig
 	Items  []item `json:"scopes"`
+	// HideUnmatched hides the scopes whose paths match no staged file, when any scope matches.
+	HideUnmatched bool `json:"hideUnmatched"`
+	// Discover adds a scope for each package of the workspaces of these tools, see discover.
+	Discover []string `json:"discover"`
+
+	// instances are the active scopes modules of the loaded set, this one included, sorted by
+	// name, see Bind. The module can be configured more than once, e.g. {"name": "team-scopes",
+	// "module": "scopes"}, and the scopes selected in every instance share the Scopes of the
+	// commit, so each instance looks up the scopes of the others here.
+	instances []*scopes
+	staged    []string // Staged files, read when any scope has paths.
+	suggested bool     // Whether the scopes of the staged files were already selected.
 }
 
 func (s *scopes) item(id string) item {
@@ -40,7 +76,105 @@ func (s *scopes) item(id string) item {
 	return item{}
 }
 
-// LoadConfig loads the scopes configuration file.
+// all returns the scopes of this instance followed by those of the other loaded instances.
+func (s *scopes) all() []item {
+	items := slices.Clone(s.Items)
+	for _, other := range s.instances {
+		if other != s {
+			items = append(items, other.Items...)
+		}
+	}
+	return items
+}
+
+// lookup returns the scope with the given id of any instance.
+func (s *scopes) lookup(id string) item {
+	for _, i := range s.all() {
+		if i.Id == id {
+			return i
+		}
+	}
+	return item{}
+}
+
+// owns tells if the scope with the given id is one of this instance.
+func (s *scopes) owns(id string) bool {
+	return s.item(id).Id != ""
+}
+
+// first tells if this is the first of the loaded instances by name, the one that reports the
+// scopes that no instance knows, so they are only reported once.
+func (s *scopes) first() bool {
+	return len(s.instances) == 0 || s.instances[0] == s
+}
+
+// Bind keeps the active scopes modules of the loaded set, see instances.
+func (s *scopes) Bind(modules []gc.Module) {
+	s.instances = nil
+	for _, m := range modules {
+		if other, ok := m.(*scopes); ok && other.IsActive() {
+			s.instances = append(s.instances, other)
+		}
+	}
+	slices.SortFunc(s.instances, func(a, b *scopes) int { return strings.Compare(a.config.Name, b.config.Name) })
+}
+
+// merge returns the scopes of the other instances in ids followed by the given ones of this instance.
+func (s *scopes) merge(ids, own []string) []string {
+	var merged []string
+	for _, id := range ids {
+		if !s.owns(id) && s.lookup(id).Id != "" {
+			merged = append(merged, id)
+		}
+	}
+	return append(merged, own...)
+}
+
+// resolve returns the scopes referenced by the given value, which can be a scope id,
+// a scope name or the marks of one or more scopes as rendered in the commit title, of this
+// or any other instance. It returns nil if the value does not reference any known scope.
+func (s *scopes) resolve(value string) []item {
+	all := s.all()
+	for _, i := range all {
+		if i.Id == value || strings.EqualFold(i.Name, value) || (i.Emoji != "" && i.Emoji == value) {
+			return []item{i}
+		}
+	}
+
+	// Scopes shown by their id are separated, each part is then resolved on its own
+	if strings.Contains(value, ScopeSeparator) {
+		var items []item
+		for _, part := range strings.Split(value, ScopeSeparator) {
+			resolved := s.resolve(strings.TrimSpace(part))
+			if resolved == nil {
+				return nil
+			}
+			items = append(items, resolved...)
+		}
+		return items
+	}
+
+	// The emojis are written together, the longest one is taken first as some emojis start
+	// like others, e.g. "🗂️" and "🗂"
+	var items []item
+	for value != "" {
+		found := item{}
+		for _, i := range all {
+			if i.Emoji != "" && strings.HasPrefix(value, i.Emoji) && len(i.Emoji) > len(found.Emoji) {
+				found = i
+			}
+		}
+		if found.Id == "" {
+			return nil
+		}
+		items = append(items, found)
+		value = strings.TrimPrefix(value, found.Emoji)
+	}
+	return items
+}
+
+// LoadConfig loads the scopes configuration file, and the scopes in the module options, which
+// are written in the same format.
 // Example:
 //
 //	{
@@ -50,74 +184,292 @@ func (s *scopes) item(id string) item {
 //	            "emoji": "📦",
 //	            "name": "Modules",
 //	            "description": "Use this when changes are made to modules",
-//	            "conditional": ["feat", "fix", "chore"]
+//	            "conditional": ["feat", "fix", "chore"],
+//	            "paths": ["types/**", "scopes/**"]
 //	        }
-//	    ]
+//	    ],
+//	    "hideUnmatched": false,
+//	    "discover": ["go", "npm", "pnpm", "cargo", "nx", "lerna"]
 //	}
 func (s *scopes) LoadConfig() error {
-
-	if s.config.Path == "" {
-		return nil
+	if s.config.Path != "" {
+		if err := gc.ReadConfigFile(s.config.Path, s); err != nil {
+			return err
+		}
 	}
 
-	raw, err := os.ReadFile(s.config.Path)
-	if err != nil {
-		fmt.Println("Error occurred while reading scopes config:", err)
-		os.Exit(1)
+	options := scopes{}
+	if err := s.config.DecodeOptions(&options); err != nil {
+		return err
+	}
+	s.Items = gc.MergeOptions(s.Items, options.Items, func(i item) string { return i.Id })
+	if options.HideUnmatched {
+		s.HideUnmatched = true
 	}
-	err = json.Unmarshal(raw, &s)
-	if err != nil {
-		fmt.Println("Error occurred while parsing scopes config:", err)
-		os.Exit(1)
+
+	// The scopes written by hand replace the discovered ones with the same id
+	s.Discover = append(s.Discover, options.Discover...)
+	if len(s.Discover) > 0 {
+		discovered, err := discover(s.Discover, s.Items)
+		if err != nil {
+			return err
+		}
+		s.Items = append(s.Items, discovered...)
 	}
 
+	for _, i := range s.Items {
+		for _, p := range i.Paths {
+			if _, err := gc.MatchPath(p, ""); err != nil {
+				return fmt.Errorf("invalid path %q of scope %q: %w", p, i.Id, err)
+			}
+		}
+	}
 	return nil
 }
 
+// hasPaths tells if any scope has paths, so the staged files are needed.
+func (s *scopes) hasPaths() bool {
+	return slices.ContainsFunc(s.Items, func(i item) bool { return len(i.Paths) > 0 })
+}
+
+// match returns the ids of the scopes whose paths match any of the staged files, and the
+// staged files that match no scope. A file of nested packages only matches the innermost one.
+func (s *scopes) match() (ids []string, unmatched []string) {
+	for _, f := range s.staged {
+		var matched []item
+		for _, i := range s.Items {
+			for _, p := range i.Paths {
+				if ok, _ := gc.MatchPath(p, f); ok {
+					matched = append(matched, i)
+					break
+				}
+			}
+		}
+		if len(matched) == 0 {
+			unmatched = append(unmatched, f)
+		}
+
+		owner := item{}
+		for _, i := range matched {
+			if i.dir == "" {
+				if !slices.Contains(ids, i.Id) {
+					ids = append(ids, i.Id)
+				}
+			} else if len(i.dir) > len(owner.dir) {
+				owner = i
+			}
+		}
+		if owner.Id != "" && !slices.Contains(ids, owner.Id) {
+			ids = append(ids, owner.Id)
+		}
+	}
+	return ids, unmatched
+}
+
+// Schema returns the JSON Schema of the scopes configuration file.
+func (s *scopes) Schema() gc.Schema {
+	return gc.NewSchema("goodcommit scopes", scopes{})
+}
+
+// CheckConfig reports the scopes that are also scopes of another instance of the module, and
+// the conditional entries of the scopes that are not types of the types module, if it is active.
+func (s *scopes) CheckConfig(modules []gc.Module) []error {
+	var errs []error
+	var typesModule []gc.Module
+	for _, m := range modules {
+		if m.Config().Type() == "types" && m.IsActive() {
+			typesModule = append(typesModule, m)
+		}
+		if other, ok := m.(*scopes); ok && other != s && other.IsActive() && other.config.Name < s.config.Name {
+			for _, i := range s.Items {
+				if other.owns(i.Id) {
+					errs = append(errs, fmt.Errorf("scope %q is also a scope of module %s", i.Id, other.config.Name))
+				}
+			}
+		}
+	}
+	if len(typesModule) == 0 {
+		return errs
+	}
+
+	for _, i := range s.Items {
+		for _, t := range i.Conditional {
+			if t != "*" && len(gc.Validate(typesModule, &gc.Commit{Type: t})) > 0 {
+				errs = append(errs, fmt.Errorf("scope %q is conditional on unknown type %q", i.Id, t))
+			}
+		}
+	}
+	return errs
+}
+
 // NewField returns a huh.MultiSelect field that allows the user to select the scopes for the commit.
-// The options are built based on the selected commit type.
+// The options are built based on the selected commit type. The scopes whose paths match the staged
+// files are selected the first time, and with hideUnmatched they are the only ones shown.
 func (s *scopes) NewField(commit *gc.Commit) (huh.Field, error) {
+	matched, unmatched := s.match()
+	if !s.suggested && !slices.ContainsFunc(commit.Scopes, s.owns) {
+		var suggested []string
+		for _, id := range matched {
+			if s.item(id).validFor(commit.Type) {
+				suggested = append(suggested, id)
+				s.suggested = true
+			}
+		}
+		commit.Scopes = s.merge(commit.Scopes, suggested)
+	}
 
+	// The field is bound to the scopes of every instance, so those selected in the other
+	// instances are options too, already selected, and kept when the field is submitted
 	var typeOptions []huh.Option[string]
+	for _, id := range commit.Scopes {
+		if !s.owns(id) {
+			if i := s.lookup(id); i.Id != "" {
+				typeOptions = append(typeOptions, i.option(commit.Type))
+			}
+		}
+	}
 	for _, i := range s.Items {
-		if slices.Contains(i.Conditional, commit.Type) {
-			typeOptions = append(typeOptions, huh.NewOption(commit.Type+"("+i.Emoji+"): "+i.Name+" - "+i.Description, i.Id))
+		if !i.validFor(commit.Type) {
+			continue
+		}
+		if s.HideUnmatched && len(matched) > 0 && !slices.Contains(matched, i.Id) && !slices.Contains(commit.Scopes, i.Id) {
+			continue
 		}
+		typeOptions = append(typeOptions, i.option(commit.Type))
 	}
 
 	if len(typeOptions) == 0 {
 		return nil, fmt.Errorf("no valid scope options found for commit type: %s", commit.Type)
 	}
 
+	description := "Additional contextual information about the changes. Multiple selections allowed.\n"
+	if len(unmatched) > 0 {
+		description += fmt.Sprintf("⚠️  No scope matches %s\n", files(unmatched))
+	}
+
 	return huh.NewMultiSelect[string]().
 		Options(typeOptions...).
 		Title("🪱・Select Commit Scopes").
-		Description("Additional contextual information about the changes. Multiple selections allowed.\n").
-		Value(&commit.Scopes), nil // commit.Scopes should be a slice of strings
+		Description(description).
+		Value(&commit.Scopes), nil
 }
 
+// files lists the first few files, saying how many more there are.
+func files(files []string) string {
+	const max = 3
+	if len(files) <= max {
+		return strings.Join(files, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(files[:max], ", "), len(files)-max)
+}
+
+// Inputs lets the scopes be given with --scope when running without a form.
+// Only ids of scopes that are valid for the commit type are accepted. Every instance of the
+// module takes its own scopes from the same --scope values.
+func (s *scopes) Inputs() []gc.Input {
+	return []gc.Input{{
+		Key:   "scope",
+		Short: "s",
+		Kind:  gc.InputList,
+		Usage: "Commit scope ids, can be repeated or comma separated",
+		Set: func(commit *gc.Commit, values []string) error {
+			var own []string
+			for _, v := range values {
+				if !s.owns(v) {
+					if s.lookup(v).Id == "" && s.first() {
+						return fmt.Errorf("unknown scope: %s", v)
+					}
+					continue
+				}
+				if commit.Type != "" && !s.item(v).validFor(commit.Type) {
+					return fmt.Errorf("scope %s is not valid for commit type: %s", v, commit.Type)
+				}
+				own = append(own, v)
+			}
+			commit.Scopes = s.merge(commit.Scopes, own)
+			return nil
+		},
+	}}
+}
+
+// resolveCommit returns the scopes of the commit, and the values of Scopes that are not known.
+// Scopes holds the names of the "SCOPE:" header of a rendered message, or the values of the
+// title scope. When a name is not known, e.g. in the space separated names of older versions,
+// the scopes are taken from the emojis of the title instead.
+func (s *scopes) resolveCommit(commit *gc.Commit) (items []item, unknown []string) {
+	for _, scope := range commit.Scopes {
+		if resolved := s.resolve(scope); resolved != nil {
+			items = append(items, resolved...)
+		} else {
+			unknown = append(unknown, scope)
+		}
+	}
+	if len(unknown) > 0 || len(items) == 0 {
+		if resolved := s.resolve(commit.Scope); resolved != nil {
+			return resolved, nil
+		}
+	}
+	return items, unknown
+}
+
+// Validate checks that the scopes of the commit exist and that those of this instance are valid
+// for the commit type.
+func (s *scopes) Validate(commit *gc.Commit) []gc.Violation {
+	var violations []gc.Violation
+	items, unknown := s.resolveCommit(commit)
+	if s.first() {
+		for _, scope := range unknown {
+			violations = append(violations, gc.Violation{Field: "scope", Message: fmt.Sprintf("unknown scope %q", scope)})
+		}
+	}
+	for _, i := range items {
+		if s.owns(i.Id) && !i.validFor(commit.Type) {
+			violations = append(violations, gc.Violation{Field: "scope", Message: fmt.Sprintf("scope %q is not valid for commit type %q", i.Id, commit.Type)})
+		}
+	}
+	return violations
+}
+
+// PostProcess sets the emojis of the selected scopes as the scope of the commit title, or their
+// marks separated by ScopeSeparator when any has no emoji, and stores their comma separated names
+// in the "scopeNames" extra for the "SCOPE:" header of the message template.
+// The scopes of every instance are included, so it doesn't matter which instance runs last.
 func (s *scopes) PostProcess(commit *gc.Commit) error {
-	scopeHeader := "SCOPE: "
-	scopeEmojis := ""
 	if len(commit.Scopes) == 0 && s.IsActive() {
 		commit.Scope = ""
+		delete(commit.Extras, "scopeNames")
 		return nil
 	}
-	if len(commit.Scopes) > 1 {
-		scopeHeader = "SCOPES: "
-	}
+	var names, marks []string
+	separate := false
 	for _, scopeId := range commit.Scopes {
 		if scopeId != "empty" {
-			scopeHeader += s.item(scopeId).Name + " "
-			scopeEmojis += s.item(scopeId).Emoji
+			i := s.lookup(scopeId)
+			names = append(names, i.Name)
+			marks = append(marks, i.mark())
+			separate = separate || i.Emoji == ""
 		}
 	}
-	commit.Scope = scopeEmojis
-	commit.Body = scopeHeader + "\n" + commit.Body
+	scopeNames := strings.Join(names, gc.ScopeNamesSeparator)
+	if separate {
+		commit.Scope = strings.Join(marks, ScopeSeparator)
+	} else {
+		commit.Scope = strings.Join(marks, "")
+	}
+	commit.Extras["scopeNames"] = &scopeNames
 
 	return nil
 }
 
+// Reset removes the scopes of this instance from the commit when the field is no longer shown,
+// and those that are not valid for the commit type when it is, e.g. a scope of feat and fix
+// commits when the type changes to docs.
+func (s *scopes) Reset(commit *gc.Commit, shown bool) {
+	commit.Scopes = slices.DeleteFunc(commit.Scopes, func(id string) bool {
+		return s.owns(id) && (!shown || !s.item(id).validFor(commit.Type))
+	})
+}
+
 func (s *scopes) Config() gc.ModuleConfig {
 	return s.config
 }
@@ -127,10 +479,27 @@ func (s *scopes) SetConfig(config gc.ModuleConfig) {
 }
 
 func (s *scopes) Name() string {
-	return MODULE_NAME
+	return s.config.Name
 }
 
+// InitCommitInfo reads the staged files if any scope has paths, and maps the scopes of an already
+// rendered commit (e.g. when amending) back to their ids, so they are selected in the field.
 func (s *scopes) InitCommitInfo(commit *gc.Commit) error {
+	if s.hasPaths() {
+		// Without staged files there is nothing to suggest
+		s.staged, _ = gc.StagedFiles()
+	}
+	if len(commit.Scopes) == 0 && commit.Scope == "" {
+		return nil
+	}
+
+	items, _ := s.resolveCommit(commit)
+	var ids []string
+	for _, i := range items {
+		ids = append(ids, i.Id)
+	}
+	commit.Scopes = ids
+	commit.Scope = ""
 	return nil
 }
 
@@ -144,3 +513,7 @@ func (s *scopes) IsActive() bool {
 func New() gc.Module {
 	return &scopes{config: gc.ModuleConfig{Name: MODULE_NAME}, Items: []item{}}
 }
+
+func init() {
+	gc.Register(MODULE_NAME, New)
+}
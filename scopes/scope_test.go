@@ -0,0 +1,74 @@
+package scopes
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+	gc "github.com/nantli/goodcommit"
+)
+
+// newInstance returns an instance of the module with the given name and scopes.
+func newInstance(t *testing.T, name, options string) *scopes {
+	t.Helper()
+	s := New().(*scopes)
+	s.SetConfig(gc.ModuleConfig{Name: name, Module: MODULE_NAME, Active: true, Options: json.RawMessage(options)})
+	if err := s.LoadConfig(); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// TestBindInstances checks that the instances of the module only see the scopes of the
+// instances loaded with them.
+func TestBindInstances(t *testing.T) {
+	api := newInstance(t, "scopes", `{"scopes": [{"id": "api", "name": "API", "emoji": "🔌"}]}`)
+	team := newInstance(t, "team-scopes", `{"scopes": [{"id": "ops", "name": "Ops", "emoji": "🚀"}]}`)
+	gc.BindModules([]gc.Module{team, api})
+	if got := api.lookup("ops").Name; got != "Ops" {
+		t.Errorf("lookup(ops) = %q, want the scope of team-scopes", got)
+	}
+	if !api.first() || team.first() {
+		t.Errorf("first() = %v and %v, want scopes to be the first instance", api.first(), team.first())
+	}
+
+	// Loading the configuration again gives a new set, without the instances of the old one
+	web := newInstance(t, "scopes", `{"scopes": [{"id": "web", "name": "Web", "emoji": "🌐"}]}`)
+	gc.BindModules([]gc.Module{web})
+	if got := web.lookup("ops").Id; got != "" {
+		t.Errorf("lookup(ops) = %q in a set without team-scopes", got)
+	}
+	if got := len(web.resolve("🔌")); got != 0 {
+		t.Errorf("resolve(🔌) found %d scopes of the old set", got)
+	}
+	if !web.first() {
+		t.Error("first() = false for the only instance")
+	}
+}
+
+// TestNewFieldKeepsOtherInstances checks that submitting the field of an instance writes its
+// scopes to the commit and keeps those selected in the other instances.
+func TestNewFieldKeepsOtherInstances(t *testing.T) {
+	api := newInstance(t, "scopes", `{"scopes": [{"id": "api", "name": "API", "emoji": "🔌", "conditional": ["*"]}]}`)
+	team := newInstance(t, "team-scopes", `{"scopes": [{"id": "ops", "name": "Ops", "emoji": "🚀"}]}`)
+	gc.BindModules([]gc.Module{api, team})
+
+	commit := &gc.Commit{Type: "feat", Scopes: []string{"ops"}, Extras: map[string]*string{}}
+	field, err := api.NewField(commit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	field = field.WithKeyMap(huh.NewDefaultKeyMap())
+	for _, msg := range []tea.KeyMsg{{Type: tea.KeyDown}, {Type: tea.KeySpace, Runes: []rune(" ")}} {
+		field.Update(msg)
+	}
+	if want := []string{"ops"}; !slices.Equal(commit.Scopes, want) {
+		t.Errorf("scopes = %q before submitting the field, want %q", commit.Scopes, want)
+	}
+	field.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if want := []string{"ops", "api"}; !slices.Equal(commit.Scopes, want) {
+		t.Errorf("scopes = %q, want %q", commit.Scopes, want)
+	}
+}
@@ -0,0 +1,337 @@
+package scopes
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	gc "github.com/nantli/goodcommit"
+	"gopkg.in/yaml.v3"
+)
+
+// sources are the workspace tools the packages of a monorepo are discovered from, see discover.
+var sources = map[string]func(w *workspace) ([]pkg, error){
+	"go":    goPackages,
+	"npm":   npmPackages,
+	"pnpm":  pnpmPackages,
+	"cargo": cargoPackages,
+	"nx":    nxPackages,
+	"lerna": lernaPackages,
+}
+
+// skippedDirs are never searched for packages.
+var skippedDirs = []string{"node_modules", "vendor", "testdata", "target", "dist", "build"}
+
+// pkg is a package of the workspace, dir is relative to the root of the repository.
+type pkg struct {
+	name string
+	dir  string
+}
+
+// workspace is the repository the packages are discovered in.
+type workspace struct {
+	root string
+	dirs []string // Every directory of the repository, relative to the root, read once.
+}
+
+// read decodes the file at the given path of the workspace, as JSON, YAML or TOML depending on
+// its extension. Unknown keys are ignored, the files belong to other tools.
+func (w *workspace) read(name string, v any) error {
+	raw, err := os.ReadFile(filepath.Join(w.root, filepath.FromSlash(name)))
+	if err != nil {
+		return err
+	}
+	switch path.Ext(name) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(raw, v)
+	case ".toml":
+		_, err = toml.Decode(string(raw), v)
+	default:
+		err = json.Unmarshal(raw, v)
+	}
+	if err != nil {
+		return fmt.Errorf("error parsing %s: %w", name, err)
+	}
+	return nil
+}
+
+// exists tells if the file at the given path of the workspace exists.
+func (w *workspace) exists(name string) bool {
+	_, err := os.Stat(filepath.Join(w.root, filepath.FromSlash(name)))
+	return err == nil
+}
+
+// walk returns the directories of the workspace, without hidden and skipped ones.
+func (w *workspace) walk() []string {
+	if w.dirs != nil {
+		return w.dirs
+	}
+	w.dirs = []string{}
+	_ = filepath.WalkDir(w.root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() || p == w.root {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") || slices.Contains(skippedDirs, d.Name()) {
+			return filepath.SkipDir
+		}
+		rel, _ := filepath.Rel(w.root, p)
+		w.dirs = append(w.dirs, filepath.ToSlash(rel))
+		return nil
+	})
+	return w.dirs
+}
+
+// glob returns the directories matching the patterns that have the given file, e.g. the
+// "packages/*" workspaces with a package.json. Patterns starting with "!" exclude directories.
+func (w *workspace) glob(patterns []string, file string) []string {
+	var dirs []string
+	for _, dir := range w.walk() {
+		matched := false
+		for _, p := range patterns {
+			exclude := strings.HasPrefix(p, "!")
+			p = path.Clean(strings.TrimPrefix(strings.TrimPrefix(p, "!"), "./"))
+			if ok, _ := gc.MatchPath(p, dir); ok {
+				matched = !exclude
+			}
+		}
+		if matched && w.exists(path.Join(dir, file)) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// find returns the directories that have the given file.
+func (w *workspace) find(file string) []string {
+	return w.glob([]string{"**"}, file)
+}
+
+// goPackages returns the modules used by go.work, or every nested go.mod without it.
+func goPackages(w *workspace) ([]pkg, error) {
+	var dirs []string
+	if f, err := os.Open(filepath.Join(w.root, "go.work")); err == nil {
+		defer f.Close()
+		block := false
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(strings.Split(scanner.Text(), "//")[0])
+			switch {
+			case line == "use (":
+				block = true
+			case block && line == ")":
+				block = false
+			case block && line != "":
+				dirs = append(dirs, line)
+			case strings.HasPrefix(line, "use "):
+				dirs = append(dirs, strings.TrimSpace(strings.TrimPrefix(line, "use ")))
+			}
+		}
+		for i, dir := range dirs {
+			dirs[i] = path.Clean(strings.Trim(dir, `"`))
+		}
+	} else {
+		dirs = w.find("go.mod")
+	}
+
+	var pkgs []pkg
+	for _, dir := range dirs {
+		module := path.Base(dir)
+		if f, err := os.Open(filepath.Join(w.root, filepath.FromSlash(dir), "go.mod")); err == nil {
+			scanner := bufio.NewScanner(f)
+			for scanner.Scan() {
+				if line := strings.TrimSpace(scanner.Text()); strings.HasPrefix(line, "module ") {
+					module = path.Base(strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module ")), `"`))
+					break
+				}
+			}
+			f.Close()
+		}
+		pkgs = append(pkgs, pkg{name: module, dir: dir})
+	}
+	return pkgs, nil
+}
+
+// packageJSON is the part of a package.json file used to discover the packages.
+type packageJSON struct {
+	Name string `json:"name"`
+	// Workspaces is a list of globs, or an object with them in "packages" (yarn).
+	Workspaces json.RawMessage `json:"workspaces"`
+}
+
+// npmPackages returns the packages of the workspaces of the package.json of the root.
+func npmPackages(w *workspace) ([]pkg, error) {
+	var root packageJSON
+	if err := w.read("package.json", &root); err != nil {
+		return nil, err
+	}
+	if len(root.Workspaces) == 0 {
+		return nil, fmt.Errorf("package.json has no workspaces")
+	}
+	var patterns []string
+	if err := json.Unmarshal(root.Workspaces, &patterns); err != nil {
+		var yarn struct {
+			Packages []string `json:"packages"`
+		}
+		if err := json.Unmarshal(root.Workspaces, &yarn); err != nil {
+			return nil, fmt.Errorf("error parsing the workspaces of package.json: %w", err)
+		}
+		patterns = yarn.Packages
+	}
+	return w.nodePackages(patterns)
+}
+
+// pnpmPackages returns the packages of pnpm-workspace.yaml.
+func pnpmPackages(w *workspace) ([]pkg, error) {
+	var config struct {
+		Packages []string `yaml:"packages"`
+	}
+	if err := w.read("pnpm-workspace.yaml", &config); err != nil {
+		return nil, err
+	}
+	return w.nodePackages(config.Packages)
+}
+
+// lernaPackages returns the packages of lerna.json, by default those in packages/*.
+func lernaPackages(w *workspace) ([]pkg, error) {
+	var config struct {
+		Packages []string `json:"packages"`
+	}
+	if err := w.read("lerna.json", &config); err != nil {
+		return nil, err
+	}
+	if len(config.Packages) == 0 {
+		config.Packages = []string{"packages/*"}
+	}
+	return w.nodePackages(config.Packages)
+}
+
+// nodePackages returns the packages in the directories matching the patterns, named by their
+// package.json.
+func (w *workspace) nodePackages(patterns []string) ([]pkg, error) {
+	var pkgs []pkg
+	for _, dir := range w.glob(patterns, "package.json") {
+		var p packageJSON
+		if err := w.read(path.Join(dir, "package.json"), &p); err != nil {
+			return nil, err
+		}
+		if p.Name == "" {
+			p.Name = path.Base(dir)
+		}
+		pkgs = append(pkgs, pkg{name: p.Name, dir: dir})
+	}
+	return pkgs, nil
+}
+
+// nxPackages returns the projects of an Nx workspace, the directories with a project.json.
+func nxPackages(w *workspace) ([]pkg, error) {
+	if !w.exists("nx.json") {
+		return nil, fmt.Errorf("nx.json not found")
+	}
+	var pkgs []pkg
+	for _, dir := range w.find("project.json") {
+		var project struct {
+			Name string `json:"name"`
+		}
+		if err := w.read(path.Join(dir, "project.json"), &project); err != nil {
+			return nil, err
+		}
+		if project.Name == "" {
+			project.Name = path.Base(dir)
+		}
+		pkgs = append(pkgs, pkg{name: project.Name, dir: dir})
+	}
+	return pkgs, nil
+}
+
+// cargoPackages returns the members of the Cargo workspace, named by their Cargo.toml.
+func cargoPackages(w *workspace) ([]pkg, error) {
+	var root struct {
+		Workspace struct {
+			Members []string `toml:"members"`
+			Exclude []string `toml:"exclude"`
+		} `toml:"workspace"`
+	}
+	if err := w.read("Cargo.toml", &root); err != nil {
+		return nil, err
+	}
+	patterns := root.Workspace.Members
+	for _, e := range root.Workspace.Exclude {
+		patterns = append(patterns, "!"+e)
+	}
+
+	var pkgs []pkg
+	for _, dir := range w.glob(patterns, "Cargo.toml") {
+		var crate struct {
+			Package struct {
+				Name string `toml:"name"`
+			} `toml:"package"`
+		}
+		if err := w.read(path.Join(dir, "Cargo.toml"), &crate); err != nil {
+			return nil, err
+		}
+		if crate.Package.Name == "" {
+			crate.Package.Name = path.Base(dir)
+		}
+		pkgs = append(pkgs, pkg{name: crate.Package.Name, dir: dir})
+	}
+	return pkgs, nil
+}
+
+// discover returns a scope for each package found by the given sources, valid for every type
+// and matching the files under the directory of the package. The id of a scope is the
+// directory of its package, which is unique. The root package, if any, is left out, every file
+// would belong to it, and so are the packages with a scope in items, the scopes written by hand.
+func discover(names []string, items []item) ([]item, error) {
+	root, err := gc.RepoRoot()
+	if err != nil {
+		return nil, err
+	}
+	w := &workspace{root: root}
+
+	var discovered []item
+	for _, name := range names {
+		source, ok := sources[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown workspace source %q", name)
+		}
+		pkgs, err := source(w)
+		if err != nil {
+			return nil, fmt.Errorf("error discovering %s packages: %w", name, err)
+		}
+		for _, p := range pkgs {
+			known := func(i item) bool { return i.Id == p.dir }
+			if p.dir == "." || slices.ContainsFunc(items, known) || slices.ContainsFunc(discovered, known) {
+				continue
+			}
+			discovered = append(discovered, item{
+				Id:          p.dir,
+				Name:        p.name,
+				Description: "Changes to " + p.dir + "/",
+				Conditional: []string{"*"},
+				Paths:       []string{p.dir + "/**"},
+				dir:         p.dir,
+			})
+		}
+	}
+	slices.SortFunc(discovered, func(a, b item) int { return strings.Compare(a.Id, b.Id) })
+
+	// Scopes are told apart by their emoji in the title, so each one gets an unused emoji, those
+	// left without one once they run out are shown by their id
+	var used []string
+	for _, i := range items {
+		used = append(used, i.Emoji)
+	}
+	for j := range discovered {
+		if discovered[j].Emoji = PickEmoji(discovered[j].Id, used); discovered[j].Emoji != "" {
+			used = append(used, discovered[j].Emoji)
+		}
+	}
+	return discovered, nil
+}
@@ -0,0 +1,149 @@
+package scopes
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	gc "github.com/nantli/goodcommit"
+)
+
+// writeFiles writes the files, by their path relative to dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// inRepo runs the test from a new git repository with the given files.
+func inRepo(t *testing.T, files map[string]string) {
+	t.Helper()
+	dir := t.TempDir()
+	if out, err := exec.Command("git", "init", "-q", dir).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v: %s", err, out)
+	}
+	writeFiles(t, dir, files)
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func TestDiscover(t *testing.T) {
+	inRepo(t, map[string]string{
+		"go.mod":                        "module example.com/root\n",
+		"go.work":                       "go 1.22\n\nuse (\n\t.\n\t./tools/gen // generators\n)\n",
+		"tools/gen/go.mod":              "module example.com/tools/gen\n",
+		"unused/go.mod":                 "module example.com/unused\n",
+		"package.json":                  `{"name": "root", "workspaces": ["packages/*", "!packages/private"]}`,
+		"packages/ui/a.txt":             "",
+		"packages/ui/package.json":      `{"name": "@acme/ui"}`,
+		"packages/private/package.json": `{"name": "private"}`,
+		"packages/web/package.json":     `{"name": "@acme/web"}`,
+		"node_modules/x/package.json":   `{"name": "x"}`,
+	})
+
+	items := []item{{Id: "packages/web", Name: "Web", Emoji: "🌐"}}
+	got, err := discover([]string{"go", "npm"}, items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids, names []string
+	for _, i := range got {
+		ids = append(ids, i.Id)
+		names = append(names, i.Name)
+		if !slices.Equal(i.Paths, []string{i.Id + "/**"}) || !slices.Equal(i.Conditional, []string{"*"}) {
+			t.Errorf("scope %q has paths %q and conditional %q", i.Id, i.Paths, i.Conditional)
+		}
+		if i.Emoji == "" || i.Emoji == "🌐" {
+			t.Errorf("scope %q has emoji %q", i.Id, i.Emoji)
+		}
+	}
+	if want := []string{"packages/ui", "tools/gen"}; !slices.Equal(ids, want) {
+		t.Errorf("ids = %q, want %q", ids, want)
+	}
+	if want := []string{"@acme/ui", "gen"}; !slices.Equal(names, want) {
+		t.Errorf("names = %q, want %q", names, want)
+	}
+
+	if _, err := discover([]string{"maven"}, nil); err == nil {
+		t.Error("discover() with an unknown source succeeded")
+	}
+	if _, err := discover([]string{"cargo"}, nil); err == nil {
+		t.Error("discover() without a Cargo.toml succeeded")
+	}
+}
+
+// TestDiscoverManyPackages checks that the scopes left without an emoji once they run out are
+// written by their ids in the title, and read back to the same scopes.
+func TestDiscoverManyPackages(t *testing.T) {
+	files := map[string]string{
+		"package.json":                `{"workspaces": ["packages/*"]}`,
+		"packages/api/package.json":   `{"name": "api"}`,
+		"packages/apigw/package.json": `{"name": "apigw"}`,
+	}
+	for n := 0; n < len(Emojis); n++ {
+		files[fmt.Sprintf("packages/p%02d/package.json", n)] = "{}"
+	}
+	inRepo(t, files)
+
+	got, err := discover([]string{"npm"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(Emojis)+2 {
+		t.Fatalf("discovered %d scopes, want %d", len(got), len(Emojis)+2)
+	}
+	var emojis []string
+	var plain []string
+	for _, i := range got {
+		if i.Emoji == "" {
+			plain = append(plain, i.Id)
+		} else if slices.Contains(emojis, i.Emoji) {
+			t.Errorf("emoji %q is used twice", i.Emoji)
+		} else {
+			emojis = append(emojis, i.Emoji)
+		}
+	}
+	if len(plain) != 2 {
+		t.Fatalf("scopes without an emoji = %q, want 2", plain)
+	}
+
+	s := New().(*scopes)
+	s.SetConfig(gc.ModuleConfig{Name: MODULE_NAME, Active: true})
+	s.Items = got
+	for _, selected := range [][]string{
+		{"packages/api", "packages/apigw"},
+		{"packages/apigw", "packages/api"},
+		{plain[0], got[0].Id, plain[1]},
+		{got[0].Id, got[1].Id},
+	} {
+		commit := gc.Commit{Scopes: selected, Extras: map[string]*string{}}
+		if err := s.PostProcess(&commit); err != nil {
+			t.Fatal(err)
+		}
+		resolved := s.resolve(commit.Scope)
+		var ids []string
+		for _, i := range resolved {
+			ids = append(ids, i.Id)
+		}
+		if !slices.Equal(ids, selected) {
+			t.Errorf("scope %q resolves to %q, want %q", commit.Scope, ids, selected)
+		}
+		if strings.Contains(commit.Scope, ScopeSeparator) != slices.ContainsFunc(selected, func(id string) bool { return slices.Contains(plain, id) }) {
+			t.Errorf("scope %q of %q", commit.Scope, selected)
+		}
+	}
+}
@@ -28,7 +28,7 @@ func (s *signedOffBy) NewField(commit *gc.Commit) (huh.Field, error) {
 }
 
 // PostProcess is called after the user has completed the goodcommit form.
-// It adds the "Signed-off-by" line to the commit footer.
+// It adds the "Signed-off-by" trailer to the commit.
 func (s *signedOffBy) PostProcess(commit *gc.Commit) error {
 	// Execute the command to get the user's name from Git config
 	nameCmd := exec.Command("git", "config", "--get", "user.name")
@@ -50,8 +50,8 @@ func (s *signedOffBy) PostProcess(commit *gc.Commit) error {
 	authorEmail := emailOut.String()
 	authorEmail = authorEmail[:len(authorEmail)-1] // Remove the newline at the end
 
-	// Append "Signed-off-by" to the commit footer with the gathered info
-	commit.Footer += fmt.Sprintf("\nSigned-off-by: %s <%s>", authorName, authorEmail)
+	// Add the "Signed-off-by" trailer with the gathered info, replacing the same one if already signed (e.g. when amending)
+	commit.AddTrailer("Signed-off-by", fmt.Sprintf("%s <%s>", authorName, authorEmail))
 	return nil
 }
 
@@ -64,7 +64,7 @@ func (s *signedOffBy) SetConfig(config gc.ModuleConfig) {
 }
 
 func (s *signedOffBy) Name() string {
-	return MODULE_NAME
+	return s.config.Name
 }
 
 func (s *signedOffBy) IsActive() bool {
@@ -78,5 +78,9 @@ func (s *signedOffBy) InitCommitInfo(commit *gc.Commit) error {
 // New returns a new instance of the signedoffby module.
 // The signedoffby module is a github.com/nantli/goodcommit module that can be used to add a "Signed-off-by" line to the commit.
 func New() gc.Module {
-	return &signedOffBy{}
+	return &signedOffBy{config: gc.ModuleConfig{Name: MODULE_NAME}}
+}
+
+func init() {
+	gc.Register(MODULE_NAME, New)
 }
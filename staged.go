@@ -0,0 +1,68 @@
+package goodcommit
+
+import (
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+// RepoRoot returns the root directory of the repository, or the working directory outside of one.
+func RepoRoot() (string, error) {
+	if out, err := exec.Command("git", "rev-parse", "--show-toplevel").Output(); err == nil {
+		return strings.TrimSpace(string(out)), nil
+	}
+	return os.Getwd()
+}
+
+// StagedFiles returns the paths of the files staged for the commit, relative to the root of
+// the repository.
+func StagedFiles() ([]string, error) {
+	out, err := exec.Command("git", "diff", "--cached", "--name-only", "-z").Output()
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, f := range strings.Split(string(out), "\x00") {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+// MatchPath reports whether name matches the glob pattern, both slash separated paths. Each
+// element of the pattern is matched as in path.Match, and a "**" element matches any number of
+// directories, so "cmd/**" matches every file under cmd and "**/*.md" every markdown file.
+// The only possible error is path.ErrBadPattern, when the pattern is malformed.
+func MatchPath(pattern, name string) (bool, error) {
+	elems := strings.Split(pattern, "/")
+	for _, e := range elems {
+		if _, err := path.Match(e, ""); err != nil {
+			return false, err
+		}
+	}
+	return matchElems(elems, strings.Split(name, "/")), nil
+}
+
+func matchElems(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Try the rest of the pattern at every depth, "**" can also match nothing
+			for i := 0; i <= len(name); i++ {
+				if matchElems(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
@@ -0,0 +1,135 @@
+package goodcommit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// dataDir returns the directory where goodcommit keeps the given kind of entries of the
+// repository, e.g. drafts. It is inside the git directory, so each worktree has its own.
+func dataDir(kind string) (string, error) {
+	out, err := exec.Command("git", "rev-parse", "--absolute-git-dir").Output()
+	if err != nil {
+		return "", errors.New("not in a git repository")
+	}
+	return filepath.Join(strings.TrimSpace(string(out)), "goodcommit", kind), nil
+}
+
+// newEntryID returns an id for a new entry of the given kind, made from the time it is created.
+// A number is added when an entry with the same id already exists, e.g. "20240102-150405-2".
+func newEntryID(kind string, now time.Time) string {
+	id := now.Format("20060102-150405")
+	dir, err := dataDir(kind)
+	if err != nil {
+		return id
+	}
+	for n := 2; ; n++ {
+		if _, err := os.Stat(filepath.Join(dir, id+".json")); errors.Is(err, fs.ErrNotExist) {
+			return id
+		}
+		id = fmt.Sprintf("%s-%d", now.Format("20060102-150405"), n)
+	}
+}
+
+// writeEntry saves v as the JSON file of the entry with the given id.
+func writeEntry(dir string, id string, v any) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("error creating %s: %w", dir, err)
+	}
+	content, err := json.MarshalIndent(v, "", "    ")
+	if err != nil {
+		return err
+	}
+	// Write and rename, so a crash while saving doesn't leave a broken entry
+	path := filepath.Join(dir, id+".json")
+	if err := os.WriteFile(path+".tmp", content, 0o644); err != nil {
+		return fmt.Errorf("error saving %s: %w", path, err)
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// removeEntry removes the file of the entry with the given id, if there is one.
+func removeEntry(dir string, id string) error {
+	err := os.Remove(filepath.Join(dir, id+".json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// readEntries reads every entry of the directory, which may not exist yet.
+func readEntries[T any](dir string) ([]*T, error) {
+	files, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*T
+	for _, f := range files {
+		if filepath.Ext(f.Name()) != ".json" {
+			continue
+		}
+		entry := new(T)
+		if err := ReadConfigFile(filepath.Join(dir, f.Name()), entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// CurrentBranch returns the name of the checked out branch, or "" if HEAD is detached.
+func CurrentBranch() string {
+	out, err := exec.Command("git", "symbolic-ref", "--quiet", "--short", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// branchExists tells if the repository has a local branch with the given name.
+func branchExists(branch string) bool {
+	return exec.Command("git", "show-ref", "--verify", "--quiet", "refs/heads/"+branch).Run() == nil
+}
+
+// Prune removes the drafts and saved messages of branches that were deleted, and returns how many
+// were removed. Entries made with a detached HEAD are kept until they are dropped.
+func Prune() (int, error) {
+	drafts, err := Drafts()
+	if err != nil {
+		return 0, err
+	}
+	messages, err := SavedMessages()
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, d := range drafts {
+		if d.Branch != "" && !branchExists(d.Branch) {
+			if err := d.Drop(); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+	}
+	for _, m := range messages {
+		if m.Branch != "" && !branchExists(m.Branch) {
+			if err := m.Drop(); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,95 @@
+package goodcommit
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// DefaultTemplateText is the text/template of the message when the configuration has none. It
+// renders the header, the body with the sections of the built-in modules and the trailers, as
+// Parse expects them, separated by a single blank line and leaving out the empty ones:
+//
+//	type(scope)!: prefix description (prefix: tickets module)
+//
+//	SCOPES: names (scopes module)
+//	WHY: why (why module)
+//
+//	body
+//
+//	emojis of the author and co-authors (coauthors module)
+//
+//	BREAKING CHANGE: message (breakingmsg module)
+//	Co-authored-by: name <email> (coauthors module)
+//	Signed-off-by: name <email> (signedoffby module)
+const DefaultTemplateText = `{{.Type}}{{with .Scope}}({{.}}){{end}}{{if .Breaking}}!{{end}}: {{with .Extra "titlePrefix"}}{{.}} {{end}}{{.Description}}
+{{- $scopes := .Extra "scopeNames"}}{{$why := .Extra "why"}}
+{{- if or $scopes $why .Body}}
+
+{{with $scopes}}{{if gt (len $.Scopes) 1}}SCOPES{{else}}SCOPE{{end}}: {{.}}{{if or $why $.Body}}
+{{end}}{{end}}
+{{- with $why}}WHY: {{.}}{{if $.Body}}
+
+{{end}}{{end}}
+{{- .Body}}{{end}}
+{{- with trim (.Extra "signature")}}
+
+{{.}}{{end}}
+{{- with trailers .Trailers}}
+
+{{.}}{{end}}{{.Footer}}`
+
+// templateFuncs are the functions available to message templates, besides the text/template ones.
+var templateFuncs = template.FuncMap{
+	"join":     strings.Join,
+	"trim":     strings.TrimSpace,
+	"upper":    strings.ToUpper,
+	"lower":    strings.ToLower,
+	"trailers": FormatTrailers,
+}
+
+// Template renders a Commit into its message. The template receives the whole commit after
+// post-processing, so e.g. {{.Type}}, {{.Body}} and {{.Extra "why"}} can be used, along with the
+// functions join, trim, upper, lower and trailers (see FormatTrailers).
+type Template struct {
+	tmpl *template.Template
+}
+
+var defaultTemplate = template.Must(template.New("default").Funcs(templateFuncs).Parse(DefaultTemplateText))
+
+// DefaultTemplate returns the template of DefaultTemplateText.
+func DefaultTemplate() *Template {
+	return &Template{tmpl: defaultTemplate}
+}
+
+// ParseTemplate parses the text of a message template.
+func ParseTemplate(text string) (*Template, error) {
+	tmpl, err := template.New("message").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid message template: %w", err)
+	}
+	return &Template{tmpl: tmpl}, nil
+}
+
+// ReadTemplate parses the message template in the file at path.
+func ReadTemplate(path string) (*Template, error) {
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading message template: %w", err)
+	}
+	tmpl, err := template.New(path).Funcs(templateFuncs).Parse(string(text))
+	if err != nil {
+		return nil, fmt.Errorf("invalid message template: %w", err)
+	}
+	return &Template{tmpl: tmpl}, nil
+}
+
+// Render returns the message of the commit.
+func (t *Template) Render(commit Commit) (string, error) {
+	var message strings.Builder
+	if err := t.tmpl.Execute(&message, commit); err != nil {
+		return "", fmt.Errorf("error rendering message: %w", err)
+	}
+	return message.String(), nil
+}
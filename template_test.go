@@ -0,0 +1,150 @@
+package goodcommit
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// str returns a pointer to s, for the extras of a commit.
+func str(s string) *string {
+	return &s
+}
+
+// templateCases are commits as the built-in modules leave them after post-processing, rendered
+// with the default template into testdata/<name>.golden.
+var templateCases = []struct {
+	name   string
+	commit Commit
+}{
+	{
+		name:   "header",
+		commit: Commit{Type: "chore", Description: "Bump deps"},
+	},
+	{
+		name: "body",
+		commit: Commit{
+			Type:        "fix",
+			Description: "Handle empty scopes",
+			Body:        "The form crashed when a type had no scopes.\n\nNow it shows an error.",
+		},
+	},
+	{
+		name: "scope",
+		commit: Commit{
+			Type:        "feat",
+			Scope:       "🔫",
+			Scopes:      []string{"goodcommit"},
+			Description: "Add parser",
+			Body:        "Body text.",
+			Extras:      map[string]*string{"scopeNames": str("Goodcommit")},
+		},
+	},
+	{
+		name: "scopes-why",
+		commit: Commit{
+			Type:        "fix",
+			Scope:       "🔫📦",
+			Scopes:      []string{"goodcommit", "modules"},
+			Description: "Fix y",
+			Body:        "Line1\nline2.",
+			Extras:      map[string]*string{"scopeNames": str("Goodcommit, Modules"), "why": str("Because.")},
+			Trailers:    []Trailer{{Key: "Signed-off-by", Value: "Alice <alice@example.com>"}},
+		},
+	},
+	{
+		name: "scope-no-body",
+		commit: Commit{
+			Type:        "feat",
+			Scope:       "🔫",
+			Scopes:      []string{"goodcommit"},
+			Description: "Add x",
+			Extras:      map[string]*string{"scopeNames": str("Goodcommit")},
+			Trailers:    []Trailer{{Key: "Signed-off-by", Value: "Alice <alice@example.com>"}},
+		},
+	},
+	{
+		name: "why-no-body",
+		commit: Commit{
+			Type:        "refactor",
+			Description: "Split the form",
+			Extras:      map[string]*string{"why": str("It was too long.")},
+		},
+	},
+	{
+		name: "breaking-no-body",
+		commit: Commit{
+			Type:        "fix",
+			Breaking:    true,
+			Description: "Y",
+			Extras:      map[string]*string{"breakingmsg": str("Gone.")},
+			Trailers:    []Trailer{{Key: "BREAKING CHANGE", Value: "Gone."}},
+		},
+	},
+	{
+		name: "coauthors",
+		commit: Commit{
+			Type:         "feat",
+			Breaking:     true,
+			Description:  "No scope",
+			Body:         "Body text.",
+			CoAuthoredBy: []string{"alice@example.com", "bob@example.com"},
+			Extras:       map[string]*string{"why": str("W."), "signature": str("🫏 🦊 🐝"), "breakingmsg": str("Api changed.")},
+			Trailers: []Trailer{
+				{Key: "BREAKING CHANGE", Value: "Api changed."},
+				{Key: "Co-authored-by", Value: "Alice <alice@example.com>"},
+				{Key: "Co-authored-by", Value: "Bob <bob@example.com>"},
+				{Key: "Signed-off-by", Value: "Carol <carol@example.com>"},
+			},
+		},
+	},
+	{
+		name: "signature-no-body",
+		commit: Commit{
+			Type:         "docs",
+			Description:  "Fix typo",
+			CoAuthoredBy: []string{"alice@example.com"},
+			Extras:       map[string]*string{"signature": str(" 🦊")},
+			Trailers:     []Trailer{{Key: "Co-authored-by", Value: "Alice <alice@example.com>"}},
+		},
+	},
+	{
+		name: "multiline-trailer",
+		commit: Commit{
+			Type:        "feat",
+			Breaking:    true,
+			Description: "Drop v1",
+			Body:        "Body text.",
+			Extras:      map[string]*string{"breakingmsg": str("The v1 API is gone.\nUse v2.")},
+			Trailers:    []Trailer{{Key: "BREAKING CHANGE", Value: "The v1 API is gone.\nUse v2."}, {Key: "Refs", Value: "ABC-123"}},
+		},
+	},
+}
+
+func TestDefaultTemplateGolden(t *testing.T) {
+	for _, tc := range templateCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := DefaultTemplate().Render(tc.commit)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			golden := filepath.Join("testdata", tc.name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != string(want) {
+				t.Errorf("rendered message differs from %s\ngot:\n%s\nwant:\n%s", golden, got, want)
+			}
+		})
+	}
+}
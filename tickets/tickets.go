@@ -0,0 +1,391 @@
+// Package tickets provides a github.com/nantli/goodcommit module for referencing issues and
+// tickets, e.g. of Jira or GitHub, in the commit. It presents the user with a text input field
+// pre-filled with the ticket ids found in the name of the current branch, and adds the tickets
+// to the commit as trailers (e.g. "Refs: ABC-123") or at the start of the description.
+package tickets
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	gc "github.com/nantli/goodcommit"
+)
+
+// MODULE_NAME is the name of the module and should be used as the name of the module in the config.json file.
+const MODULE_NAME = "tickets"
+
+// system is a ticket system, e.g. Jira, whose ticket ids have their own format.
+type system struct {
+	Name string `json:"name"`
+	// BranchPattern finds the ids in the branch name, e.g. "[A-Z]+-[0-9]+" in "feature/ABC-123-foo".
+	// When it has a group, the first group is the id.
+	BranchPattern string `json:"branchPattern"`
+	// Pattern is the format of the ids, the whole id must match it.
+	Pattern string `json:"pattern"`
+	// Format is how the id is written in the message, "{id}" is replaced by the id, e.g. "#{id}".
+	Format string `json:"format"`
+	// Trailer is the key of the trailer of each ticket, e.g. "Refs" or "Closes".
+	Trailer string `json:"trailer"`
+	// InTitle writes the tickets at the start of the description instead of in trailers.
+	InTitle bool `json:"inTitle"`
+
+	branch  *regexp.Regexp
+	pattern *regexp.Regexp
+	loose   *regexp.Regexp // The pattern ignoring case, to find the ids written wrongly.
+}
+
+// defaultSystem is used when the options have no systems, it finds Jira-like ids such as ABC-123.
+var defaultSystem = system{
+	Name:          "jira",
+	BranchPattern: `[A-Z][A-Z0-9]+-[0-9]+`,
+	Pattern:       `[A-Z][A-Z0-9]+-[0-9]+`,
+}
+
+// format returns the ticket as written in the message.
+func (s *system) format(id string) string {
+	return strings.ReplaceAll(s.Format, "{id}", id)
+}
+
+// parse returns the id of a ticket written as in the message, or as the bare id, and whether
+// it is an id of the system.
+func (s *system) parse(ticket string) (string, bool) {
+	id := s.trim(ticket)
+	return id, s.pattern.MatchString(id)
+}
+
+// resembles tells if the ticket looks like an id of the system written wrongly, e.g. "aBC-12"
+// for "ABC-12", or "#12a" with the "#{id}" format.
+func (s *system) resembles(ticket string) bool {
+	prefix, suffix, _ := strings.Cut(s.Format, "{id}")
+	if prefix != "" || suffix != "" {
+		return strings.HasPrefix(ticket, prefix) && strings.HasSuffix(ticket, suffix)
+	}
+	return s.loose.MatchString(ticket)
+}
+
+// trim removes the text around the id of the format of the system from the ticket.
+func (s *system) trim(ticket string) string {
+	prefix, suffix, _ := strings.Cut(s.Format, "{id}")
+	return strings.TrimSuffix(strings.TrimPrefix(ticket, prefix), suffix)
+}
+
+type tickets struct {
+	config   gc.ModuleConfig
+	Systems  []system `json:"systems"`
+	Required bool     `json:"required"`
+}
+
+// LoadConfig loads the module options, the ticket systems of the repository.
+// Example options:
+//
+//	"options": {
+//	    "systems": [
+//	        {
+//	            "name": "jira",
+//	            "branchPattern": "[A-Z][A-Z0-9]+-[0-9]+",
+//	            "pattern": "[A-Z][A-Z0-9]+-[0-9]+",
+//	            "trailer": "Refs"
+//	        },
+//	        {
+//	            "name": "github",
+//	            "branchPattern": "^(?:[a-z]+/)?([0-9]+)-",
+//	            "pattern": "[0-9]+",
+//	            "format": "#{id}",
+//	            "trailer": "Closes"
+//	        }
+//	    ]
+//	}
+func (t *tickets) LoadConfig() error {
+	if err := t.config.DecodeOptions(t); err != nil {
+		return err
+	}
+	if len(t.Systems) == 0 {
+		t.Systems = []system{defaultSystem}
+	}
+
+	for i := range t.Systems {
+		s := &t.Systems[i]
+		if s.Pattern == "" {
+			return fmt.Errorf("ticket system %q has no pattern", s.Name)
+		}
+		if s.Format == "" {
+			s.Format = "{id}"
+		} else if !strings.Contains(s.Format, "{id}") {
+			return fmt.Errorf("format %q of ticket system %q has no {id}", s.Format, s.Name)
+		}
+		if s.Trailer == "" {
+			s.Trailer = "Refs"
+		}
+
+		var err error
+		if s.pattern, err = regexp.Compile("^(?:" + s.Pattern + ")$"); err != nil {
+			return fmt.Errorf("invalid pattern of ticket system %q: %w", s.Name, err)
+		}
+		s.loose = regexp.MustCompile("(?i)" + s.pattern.String())
+		if s.BranchPattern != "" {
+			if s.branch, err = regexp.Compile(s.BranchPattern); err != nil {
+				return fmt.Errorf("invalid branchPattern of ticket system %q: %w", s.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// system returns the system of the ticket, and the id of the ticket in it.
+func (t *tickets) system(ticket string) (*system, string) {
+	for i := range t.Systems {
+		if id, ok := t.Systems[i].parse(ticket); ok {
+			return &t.Systems[i], id
+		}
+	}
+	return nil, ""
+}
+
+// check returns an error if any of the tickets doesn't belong to a system.
+func (t *tickets) check(tickets []string) error {
+	if t.Required && len(tickets) == 0 {
+		return fmt.Errorf("a ticket is required")
+	}
+	for _, ticket := range tickets {
+		if s, _ := t.system(ticket); s == nil {
+			return fmt.Errorf("%q is not a valid ticket id", ticket)
+		}
+	}
+	return nil
+}
+
+// NewField returns a huh.Input field for the ticket ids, separated by commas or spaces.
+func (t *tickets) NewField(commit *gc.Commit) (huh.Field, error) {
+	var names []string
+	for _, s := range t.Systems {
+		names = append(names, s.Name)
+	}
+	return huh.NewInput().
+		Title("🎫・Which tickets does this commit reference?").
+		Description(fmt.Sprintf("Ticket ids of %s, separated by commas.", strings.Join(names, " or "))).
+		Validate(func(value string) error {
+			return t.check(split(value))
+		}).
+		Value(commit.Extras[t.config.Name]), nil
+}
+
+// Inputs lets the tickets be given with --ticket when running without a form.
+func (t *tickets) Inputs() []gc.Input {
+	return []gc.Input{{
+		Key:      "ticket",
+		Kind:     gc.InputList,
+		Usage:    "Ticket ids, can be repeated or comma separated",
+		Required: t.Required,
+		Set: func(commit *gc.Commit, values []string) error {
+			if err := t.check(values); err != nil {
+				return err
+			}
+			value := strings.Join(values, ", ")
+			commit.Extras[t.config.Name] = &value
+			return nil
+		},
+	}}
+}
+
+// Validate checks that the tickets in the trailers, and at the start of the description for the
+// systems written in the title, have the format of their system.
+func (t *tickets) Validate(commit *gc.Commit) []gc.Violation {
+	var violations []gc.Violation
+	for _, s := range t.Systems {
+		if s.InTitle {
+			continue
+		}
+		for _, ticket := range commit.TrailerValues(s.Trailer) {
+			if _, ok := s.parse(ticket); !ok {
+				violations = append(violations, gc.Violation{Field: "ticket", Message: fmt.Sprintf("%q is not a valid %s ticket", ticket, s.Name)})
+			}
+		}
+	}
+	for _, word := range strings.Fields(commit.Description) {
+		if s, _ := t.titleSystem(word); s != nil {
+			continue
+		}
+		s := t.resembled(word)
+		if s == nil {
+			break
+		}
+		violations = append(violations, gc.Violation{Field: "ticket", Message: fmt.Sprintf("%q is not a valid %s ticket", word, s.Name)})
+	}
+	if t.Required && len(violations) == 0 && len(t.found(commit, false)) == 0 {
+		violations = append(violations, gc.Violation{Field: "ticket", Message: "a ticket is required"})
+	}
+	return violations
+}
+
+// PostProcess adds the tickets to the commit as trailers, or to the "titlePrefix" extra, which
+// the message template writes at the start of the description, as configured for their system.
+// The description is left as the user wrote it, so the order of the modules doesn't matter.
+func (t *tickets) PostProcess(commit *gc.Commit) error {
+	var prefix []string
+	for _, ticket := range split(commit.Extra(t.config.Name)) {
+		s, id := t.system(ticket)
+		if s == nil {
+			return fmt.Errorf("%q is not a valid ticket id", ticket)
+		}
+		if s.InTitle {
+			prefix = append(prefix, s.format(id))
+		} else {
+			commit.AddTrailer(s.Trailer, s.format(id))
+		}
+	}
+	if len(prefix) > 0 {
+		if p := commit.Extra("titlePrefix"); p != "" {
+			prefix = append([]string{p}, prefix...)
+		}
+		value := strings.Join(prefix, " ")
+		commit.Extras["titlePrefix"] = &value
+	}
+	return nil
+}
+
+// TitlePrefix returns the tickets of the systems written in the title at the start of the
+// description, so the other modules check the description without them.
+func (t *tickets) TitlePrefix(commit *gc.Commit) string {
+	rest := commit.Description
+	for {
+		word, after, _ := strings.Cut(rest, " ")
+		if s, _ := t.titleSystem(word); s == nil {
+			break
+		}
+		rest = strings.TrimLeft(after, " ")
+	}
+	return strings.TrimSuffix(commit.Description, rest)
+}
+
+// titleSystem returns the system written in the title of the ticket, and the id of the ticket in it.
+func (t *tickets) titleSystem(ticket string) (*system, string) {
+	for i := range t.Systems {
+		if !t.Systems[i].InTitle {
+			continue
+		}
+		if id, ok := t.Systems[i].parse(ticket); ok {
+			return &t.Systems[i], id
+		}
+	}
+	return nil, ""
+}
+
+// resembled returns the system written in the title whose ids the ticket looks like, if any.
+func (t *tickets) resembled(ticket string) *system {
+	for i := range t.Systems {
+		if t.Systems[i].InTitle && t.Systems[i].resembles(ticket) {
+			return &t.Systems[i]
+		}
+	}
+	return nil
+}
+
+// Reset empties the tickets when the field is no longer shown.
+func (t *tickets) Reset(commit *gc.Commit, shown bool) {
+	if !shown && commit.Extras[t.config.Name] != nil {
+		*commit.Extras[t.config.Name] = ""
+	}
+}
+
+func (t *tickets) Config() gc.ModuleConfig {
+	return t.config
+}
+
+func (t *tickets) SetConfig(config gc.ModuleConfig) {
+	t.config = config
+}
+
+func (t *tickets) Name() string {
+	return t.config.Name
+}
+
+// InitCommitInfo pre-fills the field with the tickets already in the commit (e.g. when amending),
+// which are removed from it to be added again in PostProcess, or else with the ticket ids found
+// in the name of the current branch.
+func (t *tickets) InitCommitInfo(commit *gc.Commit) error {
+	if commit.Extras[t.config.Name] != nil {
+		return nil
+	}
+	found := t.found(commit, true)
+	if len(found) == 0 && !commit.Amend {
+		found = t.fromBranch(gc.CurrentBranch())
+	}
+	value := strings.Join(found, ", ")
+	commit.Extras[t.config.Name] = &value
+	return nil
+}
+
+// found returns the tickets of the systems in the trailers and at the start of the description of
+// the commit, removing them from the commit if remove is set.
+func (t *tickets) found(commit *gc.Commit, remove bool) []string {
+	var found []string
+	prefix := t.TitlePrefix(commit)
+	for _, ticket := range strings.Fields(prefix) {
+		_, id := t.titleSystem(ticket)
+		found = append(found, id)
+	}
+	if remove {
+		commit.Description = strings.TrimPrefix(commit.Description, prefix)
+	}
+	for _, s := range t.Systems {
+		if s.InTitle {
+			continue
+		}
+		for _, ticket := range commit.TrailerValues(s.Trailer) {
+			if id, ok := s.parse(ticket); ok {
+				found = append(found, id)
+				if remove {
+					commit.Trailers = slices.DeleteFunc(commit.Trailers, func(tr gc.Trailer) bool {
+						return strings.EqualFold(tr.Key, s.Trailer) && tr.Value == ticket
+					})
+				}
+			}
+		}
+	}
+	return found
+}
+
+// fromBranch returns the ticket ids found in the branch name by the systems.
+func (t *tickets) fromBranch(branch string) []string {
+	var found []string
+	for _, s := range t.Systems {
+		if s.branch == nil {
+			continue
+		}
+		for _, match := range s.branch.FindAllStringSubmatch(branch, -1) {
+			id := match[0]
+			if len(match) > 1 {
+				id = match[1]
+			}
+			if _, ok := s.parse(id); ok && !slices.Contains(found, id) {
+				found = append(found, id)
+			}
+		}
+	}
+	return found
+}
+
+func (t *tickets) IsActive() bool {
+	return t.config.Active
+}
+
+// split returns the tickets of the value of the field, separated by commas or spaces.
+func split(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+}
+
+// New returns a new instance of the tickets module.
+// The tickets module is a github.com/nantli/goodcommit module that references the tickets of the
+// commit, found in the branch name or written by the user.
+func New() gc.Module {
+	return &tickets{config: gc.ModuleConfig{Name: MODULE_NAME}}
+}
+
+func init() {
+	gc.Register(MODULE_NAME, New)
+}
@@ -0,0 +1,173 @@
+package tickets
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	gc "github.com/nantli/goodcommit"
+	"github.com/nantli/goodcommit/description"
+)
+
+// systems are a Jira system written in the title and a GitHub one written in trailers.
+const systems = `{"systems": [
+	{"name": "jira", "pattern": "[A-Z][A-Z0-9]+-[0-9]+", "branchPattern": "[A-Z][A-Z0-9]+-[0-9]+", "inTitle": true},
+	{"name": "github", "pattern": "[0-9]+", "branchPattern": "^(?:[a-z]+/)?([0-9]+)-", "format": "#{id}", "trailer": "Closes"}
+]}`
+
+// newModule returns the module loaded with the given options.
+func newModule(t *testing.T, m gc.Module, options string) gc.Module {
+	t.Helper()
+	config := m.Config()
+	config.Active = true
+	if options != "" {
+		config.Options = json.RawMessage(options)
+	}
+	m.SetConfig(config)
+	if err := m.LoadConfig(); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// TestPostProcessOrder checks that the tickets written in the title are not changed by the
+// description module, whichever of them post-processes the commit first.
+func TestPostProcessOrder(t *testing.T) {
+	tk := newModule(t, New(), systems)
+	d := newModule(t, description.New(), "")
+	for _, order := range [][]gc.Module{{tk, d}, {d, tk}} {
+		commit := gc.Commit{Type: "feat", Description: "Add thing.", Extras: map[string]*string{}}
+		value := "ABC-12, #7"
+		commit.Extras[MODULE_NAME] = &value
+		for _, m := range order {
+			if err := m.PostProcess(&commit); err != nil {
+				t.Fatal(err)
+			}
+		}
+		message, err := gc.DefaultTemplate().Render(commit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "feat: ABC-12 add thing\n\nCloses: #7"; message != want {
+			t.Errorf("%s first: message = %q, want %q", order[0].Name(), message, want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	m := newModule(t, New(), systems)
+	d := newModule(t, description.New(), `{"maxLength": 10}`)
+	tests := []struct {
+		name    string
+		message string
+		want    []string // Parts of the violations, in order.
+	}{
+		{"title and trailer", "feat: ABC-12 add thing\n\nCloses: #7", nil},
+		{"mangled title", "feat: aBC-12 add thing", []string{`"aBC-12" is not a valid jira ticket`, "description is 16 chars long"}},
+		{"invalid trailer", "feat: add thing\n\nCloses: #x7", []string{`"#x7" is not a valid github ticket`}},
+		// The limit of the description doesn't count the tickets written before it
+		{"long with tickets", "feat: ABC-12 ABC-13 add thing!", nil},
+		{"too long", "feat: ABC-12 add many things", []string{"description is 15 chars long, max is 10"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commit, err := gc.Parse(tt.message)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, v := range gc.Validate([]gc.Module{m, d}, &commit) {
+				got = append(got, v.Message)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("violations = %q, want %q", got, tt.want)
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(got[i], want) {
+					t.Errorf("violation %d = %q, want it to contain %q", i, got[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateRequired(t *testing.T) {
+	m := newModule(t, New(), strings.Replace(systems, `{"systems"`, `{"required": true, "systems"`, 1))
+	for message, want := range map[string]bool{
+		"feat: ABC-12 add thing":              true,
+		"feat: add thing\n\nCloses: #7":       true,
+		"feat: add thing":                     false,
+		"feat: add thing\n\nRefs: ABC-12":     false,
+		"feat: add ABC-12 in the description": false,
+	} {
+		commit, err := gc.Parse(message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := len(gc.Validate([]gc.Module{m}, &commit)) == 0; got != want {
+			t.Errorf("valid(%q) = %v, want %v", message, got, want)
+		}
+	}
+}
+
+// TestAmend checks that the tickets of an amended commit are moved to the field and written
+// back in the same places.
+func TestAmend(t *testing.T) {
+	m := newModule(t, New(), systems)
+	message := "feat: ABC-12 ABC-13 add thing\n\nCloses: #7"
+	commit, err := gc.Parse(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit.Amend = true
+	if err := m.InitCommitInfo(&commit); err != nil {
+		t.Fatal(err)
+	}
+	if got := commit.Extra(MODULE_NAME); got != "ABC-12, ABC-13, 7" {
+		t.Errorf("field = %q, want %q", got, "ABC-12, ABC-13, 7")
+	}
+	if commit.Description != "add thing" || len(commit.Trailers) != 0 {
+		t.Errorf("the tickets were not removed from the commit: %q %q", commit.Description, commit.Trailers)
+	}
+
+	if err := m.PostProcess(&commit); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := gc.DefaultTemplate().Render(commit); err != nil || got != message {
+		t.Errorf("message = %q, %v, want %q", got, err, message)
+	}
+}
+
+func TestFromBranch(t *testing.T) {
+	m := newModule(t, New(), systems).(*tickets)
+	tests := []struct {
+		branch string
+		want   []string
+	}{
+		{"feature/ABC-123-foo", []string{"ABC-123"}},
+		{"feature/ABC-1-and-ABC-2", []string{"ABC-1", "ABC-2"}},
+		{"fix/42-crash", []string{"42"}},
+		{"main", nil},
+	}
+	for _, tt := range tests {
+		if got := m.fromBranch(tt.branch); !slices.Equal(got, tt.want) {
+			t.Errorf("fromBranch(%q) = %q, want %q", tt.branch, got, tt.want)
+		}
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	for _, options := range []string{
+		`{"systems": [{"name": "a"}]}`,
+		`{"systems": [{"name": "a", "pattern": "[0-9]+", "format": "#"}]}`,
+		`{"systems": [{"name": "a", "pattern": "[0-9"}]}`,
+		`{"systems": [{"name": "a", "pattern": "[0-9]+", "branchPattern": "("}]}`,
+	} {
+		m := New()
+		m.SetConfig(gc.ModuleConfig{Name: MODULE_NAME, Active: true, Options: json.RawMessage(options)})
+		if err := m.LoadConfig(); err == nil {
+			t.Errorf("LoadConfig() with %s succeeded, want an error", options)
+		}
+	}
+}
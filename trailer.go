@@ -0,0 +1,76 @@
+package goodcommit
+
+import (
+	"slices"
+	"strings"
+)
+
+// Trailer is a "Key: value" line of the trailer block at the end of the message, e.g.
+// "Signed-off-by: Alice <alice@example.com>". Values of more than one line are rendered with
+// the following lines indented, as continuation lines.
+type Trailer struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// sameKey compares trailer keys like git does, ignoring case.
+func sameKey(a, b string) bool {
+	return strings.EqualFold(a, b)
+}
+
+// AddTrailer adds the trailer at the end of the trailers. An identical trailer is removed first,
+// so adding it again, e.g. when amending, moves it instead of repeating it.
+func (c *Commit) AddTrailer(key, value string) {
+	c.Trailers = slices.DeleteFunc(c.Trailers, func(t Trailer) bool {
+		return sameKey(t.Key, key) && t.Value == value
+	})
+	c.Trailers = append(c.Trailers, Trailer{Key: key, Value: value})
+}
+
+// SetTrailer replaces the trailers with the key by a single one with the value, in the place of
+// the first of them, or adds it at the end if there is none.
+func (c *Commit) SetTrailer(key, value string) {
+	var trailers []Trailer
+	set := false
+	for _, t := range c.Trailers {
+		if !sameKey(t.Key, key) {
+			trailers = append(trailers, t)
+		} else if !set {
+			trailers = append(trailers, Trailer{Key: key, Value: value})
+			set = true
+		}
+	}
+	if !set {
+		trailers = append(trailers, Trailer{Key: key, Value: value})
+	}
+	c.Trailers = trailers
+}
+
+// RemoveTrailers removes the trailers with any of the keys.
+func (c *Commit) RemoveTrailers(keys ...string) {
+	c.Trailers = slices.DeleteFunc(c.Trailers, func(t Trailer) bool {
+		return slices.ContainsFunc(keys, func(key string) bool { return sameKey(t.Key, key) })
+	})
+}
+
+// TrailerValues returns the values of the trailers with the key, in order.
+func (c *Commit) TrailerValues(key string) []string {
+	var values []string
+	for _, t := range c.Trailers {
+		if sameKey(t.Key, key) {
+			values = append(values, t.Value)
+		}
+	}
+	return values
+}
+
+// FormatTrailers renders the trailers as git interpret-trailers does: one trailer per line,
+// without blank lines between them, and the extra lines of a value indented by a space.
+func FormatTrailers(trailers []Trailer) string {
+	lines := make([]string, 0, len(trailers))
+	for _, t := range trailers {
+		value := strings.ReplaceAll(strings.TrimSpace(t.Value), "\n", "\n ")
+		lines = append(lines, t.Key+": "+value)
+	}
+	return strings.Join(lines, "\n")
+}
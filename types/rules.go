@@ -0,0 +1,66 @@
+package types
+
+import (
+	"slices"
+
+	gc "github.com/nantli/goodcommit"
+)
+
+// rule suggests a type for the staged files that match any of its paths, e.g. "docs" for "**/*.md".
+type rule struct {
+	Type   string   `json:"type"`
+	Paths  []string `json:"paths"`
+	Weight int      `json:"weight"` // How much a file matching the rule counts for the type, 1 by default.
+}
+
+// weight returns the weight of the rule, 1 when it is not set.
+func (r rule) weight() int {
+	if r.Weight == 0 {
+		return 1
+	}
+	return r.Weight
+}
+
+// matches tells if the file matches any of the paths of the rule.
+func (r rule) matches(file string) bool {
+	for _, p := range r.Paths {
+		if ok, _ := gc.MatchPath(p, file); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// suggest ranks the types by the staged files. Each file counts for a type with the highest
+// weight of the rules of the type it matches, and the types are ordered by their total, the ones
+// without matches keeping their order after the rest. The suggested type is the best ranked one
+// that matches every file, e.g. "test" when only tests are staged, or "" if there is none.
+func suggest(rules []rule, ids []string, files []string) (order []string, suggested string) {
+	scores := make(map[string]int)
+	covered := make(map[string]int)
+	for _, f := range files {
+		best := make(map[string]int)
+		for _, r := range rules {
+			if r.matches(f) {
+				if _, ok := best[r.Type]; !ok || r.weight() > best[r.Type] {
+					best[r.Type] = r.weight()
+				}
+			}
+		}
+		for t, w := range best {
+			scores[t] += w
+			covered[t]++
+		}
+	}
+
+	order = slices.Clone(ids)
+	slices.SortStableFunc(order, func(a, b string) int {
+		return scores[b] - scores[a]
+	})
+	for _, id := range order {
+		if len(files) > 0 && covered[id] == len(files) {
+			return order, id
+		}
+	}
+	return order, ""
+}
@@ -0,0 +1,89 @@
+package types
+
+import (
+	"slices"
+	"testing"
+
+	gc "github.com/nantli/goodcommit"
+)
+
+// defaultTypes returns the types module of the built-in configuration, with its rules.
+func defaultTypes(t *testing.T) *types {
+	t.Helper()
+	cfg, err := gc.LoadConfig([]gc.ConfigLayer{gc.BuiltinLayer})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mc := range cfg.ModulesToActivate {
+		if mc.Type() == MODULE_NAME {
+			m := New().(*types)
+			m.SetConfig(mc)
+			if err := m.LoadConfig(); err != nil {
+				t.Fatal(err)
+			}
+			return m
+		}
+	}
+	t.Fatal("the built-in configuration has no types module")
+	return nil
+}
+
+func TestSuggestDefaultRules(t *testing.T) {
+	m := defaultTypes(t)
+	var ids []string
+	for _, i := range m.Items {
+		ids = append(ids, i.Id)
+	}
+
+	tests := []struct {
+		name      string
+		files     []string
+		suggested string
+		first     []string // The types expected at the start of the order.
+	}{
+		{"tests", []string{"types/rules_test.go", "scopes/testdata/go.mod"}, "test", []string{"test"}},
+		{"docs", []string{"README.md", "docs/guide.rst"}, "docs", []string{"docs"}},
+		{"ci", []string{".github/workflows/build.yml", ".gitlab-ci.yml"}, "ci", []string{"ci"}},
+		{"build", []string{"go.mod", "go.sum"}, "build", []string{"build"}},
+		{"code", []string{"types/rules.go"}, "", ids[:3]},
+		{"mixed", []string{"types/rules.go", "types/rules_test.go", "README.md"}, "", []string{"test", "docs"}},
+		// A test counts twice as much as a markdown file, so it goes first
+		{"weight", []string{"README.md", "types/rules_test.go"}, "", []string{"test", "docs"}},
+		{"weight tie", []string{"README.md", "CHANGELOG.md", "types/rules_test.go"}, "", []string{"docs", "test"}},
+		{"weight docs", []string{"README.md", "CHANGELOG.md", "LICENSE", "types/rules_test.go"}, "", []string{"docs", "test"}},
+		{"nothing staged", nil, "", ids[:3]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order, suggested := suggest(m.Rules, ids, tt.files)
+			if suggested != tt.suggested {
+				t.Errorf("suggest(%q) suggested %q, want %q", tt.files, suggested, tt.suggested)
+			}
+			if len(order) != len(ids) || !slices.Equal(order[:len(tt.first)], tt.first) {
+				t.Errorf("suggest(%q) order = %q, want it to start with %q", tt.files, order, tt.first)
+			}
+		})
+	}
+}
+
+// TestSuggestHighestWeight checks that a file counts for a type with the highest weight of the
+// rules of the type it matches, not their sum.
+func TestSuggestHighestWeight(t *testing.T) {
+	rules := []rule{
+		{Type: "docs", Paths: []string{"**/*.md"}},
+		{Type: "docs", Paths: []string{"docs/**"}, Weight: 3},
+		{Type: "chore", Paths: []string{"**"}, Weight: 2},
+	}
+	ids := []string{"feat", "chore", "docs"}
+
+	order, suggested := suggest(rules, ids, []string{"docs/guide.md"})
+	if want := []string{"docs", "chore", "feat"}; !slices.Equal(order, want) || suggested != "docs" {
+		t.Errorf("suggest() = %q, %q, want %q, %q", order, suggested, want, "docs")
+	}
+
+	// docs scores 1 for README.md, less than the 2 of chore, which also covers every file
+	order, suggested = suggest(rules, ids, []string{"README.md"})
+	if want := []string{"chore", "docs", "feat"}; !slices.Equal(order, want) || suggested != "chore" {
+		t.Errorf("suggest() = %q, %q, want %q, %q", order, suggested, want, "chore")
+	}
+}
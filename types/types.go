@@ -4,9 +4,7 @@
 package types
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/charmbracelet/huh"
@@ -28,9 +26,23 @@ const MODULE_NAME = "types"
 type types struct {
 	config gc.ModuleConfig
 	Items  []item `json:"types"`
+	Rules  []rule `json:"rules"`
+
+	staged    []string // Staged files, read when there are rules.
+	suggested bool     // Whether the type of the staged files was already selected.
+}
+
+func (t *types) item(id string) item {
+	for _, i := range t.Items {
+		if i.Id == id {
+			return i
+		}
+	}
+	return item{}
 }
 
-// LoadConfig loads the types configuration file.
+// LoadConfig loads the types configuration file, and the types in the module options, which
+// are written in the same format.
 // Example config file:
 //
 //	{
@@ -42,33 +54,61 @@ type types struct {
 //	            "description": "A new feature",
 //	            "emoji": "✨"
 //	        }
+//	    ],
+//	    "rules": [
+//	        { "type": "docs", "paths": ["**/*.md", "docs/**"] },
+//	        { "type": "test", "paths": ["**/*_test.go"], "weight": 2 }
 //	    ]
 //	}
 func (t *types) LoadConfig() error {
-
-	if t.config.Path == "" {
-		return nil
+	if t.config.Path != "" {
+		if err := gc.ReadConfigFile(t.config.Path, t); err != nil {
+			return err
+		}
 	}
 
-	raw, err := os.ReadFile(t.config.Path)
-	if err != nil {
-		fmt.Println("Error occurred while reading types config:", err)
-		os.Exit(1)
+	options := types{}
+	if err := t.config.DecodeOptions(&options); err != nil {
+		return err
 	}
-	err = json.Unmarshal(raw, &t)
-	if err != nil {
-		fmt.Println("Error occurred while parsing types config:", err)
-		os.Exit(1)
+	t.Items = gc.MergeOptions(t.Items, options.Items, func(i item) string { return i.Id })
+
+	t.Rules = append(t.Rules, options.Rules...)
+	for _, r := range t.Rules {
+		if t.item(r.Type).Id == "" {
+			return fmt.Errorf("rule for unknown type %q", r.Type)
+		}
+		for _, p := range r.Paths {
+			if _, err := gc.MatchPath(p, ""); err != nil {
+				return fmt.Errorf("invalid path %q of the rule for type %q: %w", p, r.Type, err)
+			}
+		}
 	}
-
 	return nil
 }
 
+// Schema returns the JSON Schema of the types configuration file.
+func (t *types) Schema() gc.Schema {
+	return gc.NewSchema("goodcommit types", types{})
+}
+
 // NewField returns a huh.Select field that allows the user to select the type of the commit.
+// With rules, the types are ordered by how well they match the staged files, and the type that
+// matches all of them is selected the first time.
 func (t *types) NewField(commit *gc.Commit) (huh.Field, error) {
+	var ids []string
+	for _, i := range t.Items {
+		ids = append(ids, i.Id)
+	}
+	ids, suggested := suggest(t.Rules, ids, t.staged)
+	if !t.suggested && commit.Type == "" && suggested != "" {
+		commit.Type = suggested
+		t.suggested = true
+	}
 
 	var typeOptions []huh.Option[string]
-	for _, i := range t.Items {
+	for _, id := range ids {
+		i := t.item(id)
 		typeOptions = append(typeOptions, huh.NewOption(i.Emoji+" "+i.Name+" - "+i.Title, i.Id))
 	}
 	return huh.NewSelect[string]().
@@ -78,6 +118,38 @@ func (t *types) NewField(commit *gc.Commit) (huh.Field, error) {
 		Value(&commit.Type), nil
 }
 
+// Inputs lets the commit type be given with --type when running without a form.
+func (t *types) Inputs() []gc.Input {
+	return []gc.Input{{
+		Key:      "type",
+		Short:    "t",
+		Usage:    "Commit type id, as defined in the types configuration file",
+		Required: true,
+		Set: func(commit *gc.Commit, values []string) error {
+			if len(t.Items) > 0 && t.item(values[0]).Id == "" {
+				return fmt.Errorf("unknown commit type: %s", values[0])
+			}
+			commit.Type = values[0]
+			return nil
+		},
+	}}
+}
+
+// Validate checks that the commit has a type and that it is one of the configured types.
+func (t *types) Validate(commit *gc.Commit) []gc.Violation {
+	if commit.Type == "" {
+		return []gc.Violation{{Field: "type", Message: "commit type is required"}}
+	}
+	if len(t.Items) > 0 && t.item(commit.Type).Id == "" {
+		var ids []string
+		for _, i := range t.Items {
+			ids = append(ids, i.Id)
+		}
+		return []gc.Violation{{Field: "type", Message: fmt.Sprintf("type %q is not one of: %s", commit.Type, strings.Join(ids, ", "))}}
+	}
+	return nil
+}
+
 func (t *types) PostProcess(commit *gc.Commit) error {
 	if commit.Type == "" && t.IsActive() {
 		return fmt.Errorf("commit type is required")
@@ -95,10 +167,15 @@ func (t *types) SetConfig(config gc.ModuleConfig) {
 }
 
 func (t *types) Name() string {
-	return MODULE_NAME
+	return t.config.Name
 }
 
+// InitCommitInfo reads the staged files the rules suggest a type from.
 func (t *types) InitCommitInfo(commit *gc.Commit) error {
+	if len(t.Rules) > 0 {
+		// Without staged files there is nothing to suggest
+		t.staged, _ = gc.StagedFiles()
+	}
 	return nil
 }
 
@@ -112,3 +189,7 @@ func (t *types) IsActive() bool {
 func New() gc.Module {
 	return &types{config: gc.ModuleConfig{Name: MODULE_NAME}, Items: []item{}}
 }
+
+func init() {
+	gc.Register(MODULE_NAME, New)
+}
@@ -0,0 +1,65 @@
+package goodcommit
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Violation describes a rule of a module that a commit does not follow.
+type Violation struct {
+	Module  string `json:"module"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (v Violation) String() string {
+	if v.Module == "" {
+		return fmt.Sprintf("%s: %s", v.Field, v.Message)
+	}
+	return fmt.Sprintf("%s/%s: %s", v.Module, v.Field, v.Message)
+}
+
+// Validator is implemented by modules that can check an already written commit against
+// the same rules they enforce in the form, for example when linting commit messages.
+type Validator interface {
+	Validate(commit *Commit) []Violation
+}
+
+// TitlePrefixer is implemented by modules that write at the start of the description, e.g. the
+// ticket ids of the tickets module, so the other modules check the description without them.
+type TitlePrefixer interface {
+	// TitlePrefix returns what the module wrote at the start of the description, with the space
+	// that separates it from the rest, or "" if nothing.
+	TitlePrefix(commit *Commit) string
+}
+
+// Validate runs the validation of every active module over the commit and returns all the
+// violations found, tagged with the name of the module that reported them. The modules that
+// wrote a title prefix validate the whole description, and the others the description without it.
+func Validate(modules []Module, commit *Commit) []Violation {
+	unprefixed := *commit
+	for _, m := range modules {
+		if p, ok := m.(TitlePrefixer); ok && m.IsActive() {
+			unprefixed.Description = strings.TrimPrefix(unprefixed.Description, p.TitlePrefix(&unprefixed))
+		}
+	}
+
+	var violations []Violation
+	for _, m := range modules {
+		v, ok := m.(Validator)
+		if !ok || !m.IsActive() {
+			continue
+		}
+		c := &unprefixed
+		if _, ok := m.(TitlePrefixer); ok {
+			c = commit
+		}
+		for _, violation := range v.Validate(c) {
+			if violation.Module == "" {
+				violation.Module = m.Name()
+			}
+			violations = append(violations, violation)
+		}
+	}
+	return violations
+}
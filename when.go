@@ -0,0 +1,492 @@
+package goodcommit
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Condition is a compiled "when" expression of a module configuration, a small boolean
+// expression over the fields of the commit:
+//
+//	type in ["feat", "fix"] && !breaking
+//	len(scopes) > 0
+//	extras.ticket == ""
+//
+// The fields are the JSON fields of Commit: type, scope, description, body and footer are
+// strings, breaking and amend are booleans, scopes and coAuthoredBy are lists of strings, and
+// extras.<key> is the string stored under key, or "" if there is none. Literals are strings in
+// double or single quotes, with the escape sequences of Go strings and \' in single quoted ones,
+// integers, true, false and lists of strings in brackets.
+//
+// The operators are ||, &&, !, == and != on values of the same type, <, <=, > and >= on
+// numbers, and in, which tells if a string is in a list or a substring of another string.
+// len returns the length of a string or a list. Parentheses group as usual.
+//
+// Expressions are type checked when parsed, so a Condition that parses always evaluates.
+type Condition struct {
+	source string
+	root   node
+}
+
+// ConditionError is returned when a "when" expression can't be parsed. Column is the position
+// of the problem in the expression, starting at 1.
+type ConditionError struct {
+	Expr   string
+	Column int
+	Msg    string
+}
+
+func (e *ConditionError) Error() string {
+	return fmt.Sprintf("invalid when %q at column %d: %s", e.Expr, e.Column, e.Msg)
+}
+
+// ParseCondition parses and type checks a "when" expression.
+func ParseCondition(expr string) (*Condition, error) {
+	p := &parser{source: expr}
+	if err := p.scan(); err != nil {
+		return nil, err
+	}
+	root, t, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, p.errorf(tok, "unexpected %s", tok)
+	}
+	if t != typeBool {
+		return nil, &ConditionError{Expr: expr, Column: 1, Msg: fmt.Sprintf("expression is a %s, not a bool", t)}
+	}
+	return &Condition{source: expr, root: root}, nil
+}
+
+// Eval tells if the condition holds for the commit.
+func (c *Condition) Eval(commit *Commit) bool {
+	return c.root.eval(commit).(bool)
+}
+
+func (c *Condition) String() string {
+	return c.source
+}
+
+// Shown tells if the module of the configuration applies to the commit, that is, if it has no
+// When expression or its expression holds. An invalid expression, which LoadConfigToModules
+// reports, never holds.
+func (c ModuleConfig) Shown(commit *Commit) bool {
+	if c.When == "" {
+		return true
+	}
+	cond, err := ParseCondition(c.When)
+	return err == nil && cond.Eval(commit)
+}
+
+// valueType is the static type of an expression.
+type valueType string
+
+const (
+	typeString valueType = "string"
+	typeNumber valueType = "number"
+	typeBool   valueType = "bool"
+	typeList   valueType = "list"
+)
+
+// node is an expression, evaluating to a string, int, bool or []string as per its type.
+type node interface {
+	eval(commit *Commit) any
+}
+
+type literal struct{ value any }
+
+func (n literal) eval(*Commit) any { return n.value }
+
+type field func(commit *Commit) any
+
+func (n field) eval(commit *Commit) any { return n(commit) }
+
+type unary func(v any) any
+
+type unaryNode struct {
+	op      unary
+	operand node
+}
+
+func (n unaryNode) eval(commit *Commit) any { return n.op(n.operand.eval(commit)) }
+
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+func (n binaryNode) eval(commit *Commit) any {
+	switch n.op {
+	case "&&":
+		return n.left.eval(commit).(bool) && n.right.eval(commit).(bool)
+	case "||":
+		return n.left.eval(commit).(bool) || n.right.eval(commit).(bool)
+	}
+	left, right := n.left.eval(commit), n.right.eval(commit)
+	switch n.op {
+	case "==":
+		return left == right
+	case "!=":
+		return left != right
+	case "<":
+		return left.(int) < right.(int)
+	case "<=":
+		return left.(int) <= right.(int)
+	case ">":
+		return left.(int) > right.(int)
+	case ">=":
+		return left.(int) >= right.(int)
+	case "in":
+		if list, ok := right.([]string); ok {
+			return slices.Contains(list, left.(string))
+		}
+		return strings.Contains(right.(string), left.(string))
+	}
+	panic("unknown operator " + n.op)
+}
+
+type listNode []node
+
+func (n listNode) eval(commit *Commit) any {
+	list := make([]string, len(n))
+	for i, item := range n {
+		list[i] = item.eval(commit).(string)
+	}
+	return list
+}
+
+// fields are the commit fields available to expressions, besides extras.<key>.
+var fields = map[string]struct {
+	t   valueType
+	get field
+}{
+	"type":         {typeString, func(c *Commit) any { return c.Type }},
+	"scope":        {typeString, func(c *Commit) any { return c.Scope }},
+	"description":  {typeString, func(c *Commit) any { return c.Description }},
+	"body":         {typeString, func(c *Commit) any { return c.Body }},
+	"footer":       {typeString, func(c *Commit) any { return c.Footer }},
+	"breaking":     {typeBool, func(c *Commit) any { return c.Breaking }},
+	"amend":        {typeBool, func(c *Commit) any { return c.Amend }},
+	"scopes":       {typeList, func(c *Commit) any { return append([]string{}, c.Scopes...) }},
+	"coAuthoredBy": {typeList, func(c *Commit) any { return append([]string{}, c.CoAuthoredBy...) }},
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokOp
+)
+
+type token struct {
+	kind  tokenKind
+	text  string // The identifier, operator or number, or the unquoted string.
+	pos   int    // Byte offset in the expression.
+	value any
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of expression"
+	case tokString:
+		return strconv.Quote(t.text)
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+type parser struct {
+	source string
+	tokens []token
+	next   int
+}
+
+func (p *parser) errorf(tok token, format string, args ...any) error {
+	return &ConditionError{Expr: p.source, Column: tok.pos + 1, Msg: fmt.Sprintf(format, args...)}
+}
+
+// scan splits the expression into tokens.
+func (p *parser) scan() error {
+	src := p.source
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"' || c == '\'':
+			end := i + 1
+			for end < len(src) && src[end] != src[i] {
+				if src[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(src) {
+				return p.errorf(token{pos: i}, "unterminated string")
+			}
+			text, err := unquote(src[i+1:end], src[i])
+			if err != nil {
+				return p.errorf(token{pos: i}, "invalid string: %v", err)
+			}
+			p.tokens = append(p.tokens, token{kind: tokString, text: text, pos: i})
+			i = end + 1
+		case c >= '0' && c <= '9':
+			end := i
+			for end < len(src) && src[end] >= '0' && src[end] <= '9' {
+				end++
+			}
+			n, err := strconv.Atoi(src[i:end])
+			if err != nil {
+				return p.errorf(token{pos: i}, "number %s is out of range", src[i:end])
+			}
+			p.tokens = append(p.tokens, token{kind: tokNumber, text: src[i:end], pos: i, value: n})
+			i = end
+		case c == '_' || unicode.IsLetter(c):
+			end := i
+			for end < len(src) && (src[end] == '_' || src[end] == '.' || src[end] == '-' || unicode.IsLetter(rune(src[end])) || unicode.IsDigit(rune(src[end]))) {
+				end++
+			}
+			p.tokens = append(p.tokens, token{kind: tokIdent, text: src[i:end], pos: i})
+			i = end
+		default:
+			op := ""
+			for _, o := range []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "!", "(", ")", "[", "]", ","} {
+				if strings.HasPrefix(src[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return p.errorf(token{pos: i}, "unexpected character %q", src[i])
+			}
+			p.tokens = append(p.tokens, token{kind: tokOp, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	p.tokens = append(p.tokens, token{kind: tokEOF, pos: len(src)})
+	return nil
+}
+
+// unquote replaces the escape sequences of a string quoted by the given quote, those of Go
+// strings, with \' in single quoted ones.
+func unquote(s string, quote byte) (string, error) {
+	var b strings.Builder
+	for s != "" {
+		r, multibyte, tail, err := strconv.UnquoteChar(s, quote)
+		if err != nil {
+			return "", err
+		}
+		if multibyte {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte(byte(r))
+		}
+		s = tail
+	}
+	return b.String(), nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *parser) take() token {
+	tok := p.tokens[p.next]
+	if tok.kind != tokEOF {
+		p.next++
+	}
+	return tok
+}
+
+// accept takes the next token if it is the given operator or keyword.
+func (p *parser) accept(text string) (token, bool) {
+	tok := p.peek()
+	if (tok.kind == tokOp || tok.kind == tokIdent) && tok.text == text {
+		return p.take(), true
+	}
+	return tok, false
+}
+
+func (p *parser) expect(text string) error {
+	if tok, ok := p.accept(text); !ok {
+		return p.errorf(tok, "expected %q, found %s", text, tok)
+	}
+	return nil
+}
+
+// parseOr parses a || b || ...
+func (p *parser) parseOr() (node, valueType, error) {
+	return p.parseLogical("||", p.parseAnd)
+}
+
+// parseAnd parses a && b && ...
+func (p *parser) parseAnd() (node, valueType, error) {
+	return p.parseLogical("&&", p.parseComparison)
+}
+
+func (p *parser) parseLogical(op string, operand func() (node, valueType, error)) (node, valueType, error) {
+	left, t, err := operand()
+	if err != nil {
+		return nil, "", err
+	}
+	for {
+		tok, ok := p.accept(op)
+		if !ok {
+			return left, t, nil
+		}
+		right, rt, err := operand()
+		if err != nil {
+			return nil, "", err
+		}
+		if t != typeBool || rt != typeBool {
+			return nil, "", p.errorf(tok, "%s needs bool operands, found %s and %s", op, t, rt)
+		}
+		left = binaryNode{op: op, left: left, right: right}
+	}
+}
+
+// parseComparison parses a single comparison, e.g. a == b or a in b.
+func (p *parser) parseComparison() (node, valueType, error) {
+	left, lt, err := p.parseUnary()
+	if err != nil {
+		return nil, "", err
+	}
+	tok := p.peek()
+	if !slices.Contains([]string{"==", "!=", "<", "<=", ">", ">=", "in"}, tok.text) || tok.kind == tokString || tok.kind == tokNumber {
+		return left, lt, nil
+	}
+	p.take()
+	right, rt, err := p.parseUnary()
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch tok.text {
+	case "==", "!=":
+		if lt != rt || lt == typeList {
+			return nil, "", p.errorf(tok, "can't compare %s and %s with %s", lt, rt, tok.text)
+		}
+	case "in":
+		if lt != typeString || (rt != typeList && rt != typeString) {
+			return nil, "", p.errorf(tok, "in needs a string and a list or string, found %s and %s", lt, rt)
+		}
+	default:
+		if lt != typeNumber || rt != typeNumber {
+			return nil, "", p.errorf(tok, "%s needs number operands, found %s and %s", tok.text, lt, rt)
+		}
+	}
+	return binaryNode{op: tok.text, left: left, right: right}, typeBool, nil
+}
+
+// parseUnary parses !a, or a primary expression.
+func (p *parser) parseUnary() (node, valueType, error) {
+	if tok, ok := p.accept("!"); ok {
+		operand, t, err := p.parseUnary()
+		if err != nil {
+			return nil, "", err
+		}
+		if t != typeBool {
+			return nil, "", p.errorf(tok, "! needs a bool operand, found %s", t)
+		}
+		return unaryNode{op: func(v any) any { return !v.(bool) }, operand: operand}, typeBool, nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary parses a literal, a field, a call to len, a list or a parenthesized expression.
+func (p *parser) parsePrimary() (node, valueType, error) {
+	tok := p.take()
+	switch tok.kind {
+	case tokString:
+		return literal{tok.text}, typeString, nil
+	case tokNumber:
+		return literal{tok.value}, typeNumber, nil
+	case tokEOF:
+		return nil, "", p.errorf(tok, "unexpected end of expression")
+	case tokIdent:
+		return p.parseIdent(tok)
+	}
+
+	switch tok.text {
+	case "(":
+		n, t, err := p.parseOr()
+		if err != nil {
+			return nil, "", err
+		}
+		return n, t, p.expect(")")
+	case "[":
+		var list listNode
+		for {
+			if _, ok := p.accept("]"); ok {
+				return list, typeList, nil
+			}
+			if len(list) > 0 {
+				if err := p.expect(","); err != nil {
+					return nil, "", err
+				}
+			}
+			item := p.peek()
+			n, t, err := p.parseOr()
+			if err != nil {
+				return nil, "", err
+			}
+			if t != typeString {
+				return nil, "", p.errorf(item, "lists can only hold strings, found %s", t)
+			}
+			list = append(list, n)
+		}
+	}
+	return nil, "", p.errorf(tok, "unexpected %s", tok)
+}
+
+// parseIdent parses a keyword, a field or a call to len.
+func (p *parser) parseIdent(tok token) (node, valueType, error) {
+	switch tok.text {
+	case "true", "false":
+		return literal{tok.text == "true"}, typeBool, nil
+	case "len":
+		if err := p.expect("("); err != nil {
+			return nil, "", err
+		}
+		arg := p.peek()
+		n, t, err := p.parseOr()
+		if err != nil {
+			return nil, "", err
+		}
+		if t != typeString && t != typeList {
+			return nil, "", p.errorf(arg, "len needs a string or list, found %s", t)
+		}
+		length := func(v any) any {
+			if list, ok := v.([]string); ok {
+				return len(list)
+			}
+			return len([]rune(v.(string)))
+		}
+		return unaryNode{op: length, operand: n}, typeNumber, p.expect(")")
+	}
+
+	if key, ok := strings.CutPrefix(tok.text, "extras."); ok && key != "" {
+		return field(func(c *Commit) any {
+			if v := c.Extras[key]; v != nil {
+				return *v
+			}
+			return ""
+		}), typeString, nil
+	}
+	if f, ok := fields[tok.text]; ok {
+		return f.get, f.t, nil
+	}
+	var names []string
+	for name := range fields {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return nil, "", p.errorf(tok, "unknown field %q, expected one of %s or extras.<key>", tok.text, strings.Join(names, ", "))
+}
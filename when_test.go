@@ -0,0 +1,125 @@
+package goodcommit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestConditionEval(t *testing.T) {
+	ticket := "ABC-12"
+	commit := &Commit{
+		Type:         "feat",
+		Scope:        "🔌",
+		Scopes:       []string{"api", "cli"},
+		Description:  "add thing",
+		Breaking:     true,
+		CoAuthoredBy: []string{"bob@example.com"},
+		Extras:       map[string]*string{"ticket": &ticket},
+	}
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		// && binds tighter than ||, ! tighter than both, parentheses group
+		{`true || false && false`, true},
+		{`(true || false) && false`, false},
+		{`!false && false`, false},
+		{`!(false && false)`, true},
+		{`!breaking || type == "feat"`, true},
+		{`type == "fix" || type == "feat" && breaking`, true},
+		{`type == "fix" || type == "feat" && !breaking`, false},
+
+		{`type in ["feat", "fix"]`, true},
+		{`type in ["docs"]`, false},
+		{`type in []`, false},
+		{`"api" in scopes`, true},
+		{`"web" in scopes`, false},
+		{`"thing" in description`, true},
+		{`"Thing" in description`, false},
+		{`"bob@example.com" in coAuthoredBy`, true},
+
+		{`len(scopes) == 2`, true},
+		{`len(scopes) > 1 && len(scopes) <= 2`, true},
+		{`len(scope) == 1`, true},
+		{`len(description) >= 10`, false},
+		{`len(body) < 1`, true},
+		{`2 != 3`, true},
+		{`amend == false`, true},
+
+		{`extras.ticket == "ABC-12"`, true},
+		{`extras.missing == ""`, true},
+		{`extras.ticket != ''`, true},
+
+		{`description == 'add thing'`, true},
+		{`'it\'s' == "it's"`, true},
+		{`'say "hi"' == "say \"hi\""`, true},
+		{`'a\tb' == "a	b"`, true},
+		{`'é' == "é"`, true},
+	}
+	for _, tt := range tests {
+		cond, err := ParseCondition(tt.expr)
+		if err != nil {
+			t.Errorf("ParseCondition(%s) error = %v", tt.expr, err)
+			continue
+		}
+		if got := cond.Eval(commit); got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseConditionErrors(t *testing.T) {
+	tests := []struct {
+		expr   string
+		column int
+		msg    string // Part of the message.
+	}{
+		{`len(scopes) > 99999999999999999999`, 15, "out of range"},
+		{`99999999999999999999 > 1`, 1, "out of range"},
+		{`type == "feat`, 9, "unterminated string"},
+		{`type == 'it\'s`, 9, "unterminated string"},
+		{`type == 'a\qb'`, 9, "invalid string"},
+		{`type == "a\qb"`, 9, "invalid string"},
+		{`tpye == "feat"`, 1, `unknown field "tpye"`},
+		{`extras. == ""`, 1, "unknown field"},
+		{`type`, 1, "is a string, not a bool"},
+		{`type == 1`, 6, "can't compare string and number"},
+		{`scopes == ["api"]`, 8, "can't compare list and list"},
+		{`scopes in ["api"]`, 8, "in needs a string"},
+		{`type > "a"`, 6, "> needs number operands"},
+		{`!type`, 1, "! needs a bool operand"},
+		{`breaking && type`, 10, "&& needs bool operands"},
+		{`[1] == []`, 2, "lists can only hold strings"},
+		{`len(breaking) > 0`, 5, "len needs a string or list"},
+		{`(breaking`, 10, `expected ")"`},
+		{`breaking breaking`, 10, "unexpected"},
+		{`type == `, 9, "unexpected end of expression"},
+		{`type = "feat"`, 6, "unexpected character"},
+	}
+	for _, tt := range tests {
+		_, err := ParseCondition(tt.expr)
+		var condErr *ConditionError
+		if !errors.As(err, &condErr) {
+			t.Errorf("ParseCondition(%s) error = %v, want a ConditionError", tt.expr, err)
+			continue
+		}
+		if condErr.Column != tt.column || !strings.Contains(condErr.Msg, tt.msg) {
+			t.Errorf("ParseCondition(%s) error = %v, want column %d and %q", tt.expr, err, tt.column, tt.msg)
+		}
+	}
+}
+
+func TestModuleConfigShown(t *testing.T) {
+	commit := &Commit{Type: "docs"}
+	for when, want := range map[string]bool{
+		"":                    true,
+		`type == "docs"`:      true,
+		`type == "feat"`:      false,
+		`unknownField == "x"`: false,
+	} {
+		if got := (ModuleConfig{When: when}).Shown(commit); got != want {
+			t.Errorf("Shown() with when %q = %v, want %v", when, got, want)
+		}
+	}
+}
@@ -1,10 +1,11 @@
 // Package why provides a github.com/nantli/goodcommit module that can be used to prompt the user
 // to explain why the change was needed. It presents the user with a text input field and
-// is then added to the commit body.
+// the reason is then added to the commit body by the message template.
 package why
 
 import (
 	"fmt"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/huh"
 	gc "github.com/nantli/goodcommit"
@@ -14,24 +15,63 @@ import (
 
 const MODULE_NAME = "why"
 
+// DEFAULT_MAX_LENGTH is the maximum length of the reason when the options don't set one.
+const DEFAULT_MAX_LENGTH = 100
+
 type why struct {
-	config gc.ModuleConfig
+	config    gc.ModuleConfig
+	MaxLength int `json:"maxLength"`
 }
 
+// LoadConfig loads the module options.
+// Example options:
+//
+//	"options": {
+//	    "maxLength": 200
+//	}
 func (w *why) LoadConfig() error {
-	return nil
+	w.MaxLength = DEFAULT_MAX_LENGTH
+	return w.config.DecodeOptions(w)
 }
 
 // NewField returns a new huh.Input field for the user to explain why the change was needed.
 func (w *why) NewField(commit *gc.Commit) (huh.Field, error) {
 	return huh.NewInput().
 		Title("❔・Why was this change needed?").
-		Description("Explain the reason for this change (max 100 chars).").
-		CharLimit(100).
+		Description(fmt.Sprintf("Explain the reason for this change (max %d chars).", w.MaxLength)).
+		CharLimit(w.MaxLength).
 		Value(commit.Extras["why"]), nil
 }
 
-// PostProcess prepends the value of the Why field to the commit body
+// Inputs lets the reason for the change be given with --why when running without a form.
+func (w *why) Inputs() []gc.Input {
+	return []gc.Input{{
+		Key:   "why",
+		Usage: "Reason for the change",
+		Set: func(commit *gc.Commit, values []string) error {
+			if utf8.RuneCountInString(values[0]) > w.MaxLength {
+				return fmt.Errorf("why is longer than %d chars", w.MaxLength)
+			}
+			why := values[0]
+			commit.Extras["why"] = &why
+			return nil
+		},
+	}}
+}
+
+// Validate checks that the reason for the change is not longer than the maximum length.
+func (w *why) Validate(commit *gc.Commit) []gc.Violation {
+	if commit.Extras["why"] == nil {
+		return nil
+	}
+	if n := utf8.RuneCountInString(*commit.Extras["why"]); n > w.MaxLength {
+		return []gc.Violation{{Field: "why", Message: fmt.Sprintf("why is %d chars long, max is %d", n, w.MaxLength)}}
+	}
+	return nil
+}
+
+// PostProcess formats the value of the Why field as a sentence, the message template adds it to
+// the commit body.
 func (w *why) PostProcess(commit *gc.Commit) error {
 	if commit.Extras["why"] == nil || *commit.Extras["why"] == "" {
 		return nil
@@ -43,11 +83,16 @@ func (w *why) PostProcess(commit *gc.Commit) error {
 	if (*commit.Extras["why"])[len(*commit.Extras["why"])-1] != '.' {
 		*commit.Extras["why"] += "."
 	}
-
-	commit.Body = fmt.Sprintf("WHY: %s\n\n%s", *commit.Extras["why"], commit.Body)
 	return nil
 }
 
+// Reset empties the why when the field is no longer shown.
+func (w *why) Reset(commit *gc.Commit, shown bool) {
+	if !shown && commit.Extras["why"] != nil {
+		*commit.Extras["why"] = ""
+	}
+}
+
 func (w *why) Config() gc.ModuleConfig {
 	return w.config
 }
@@ -57,11 +102,15 @@ func (w *why) SetConfig(config gc.ModuleConfig) {
 }
 
 func (w *why) Name() string {
-	return MODULE_NAME
+	return w.config.Name
 }
 
-// InitCommitInfo initializes the commit with a placeholder for the why field in the extras map.
+// InitCommitInfo initializes the commit with a placeholder for the why field in the extras map,
+// unless the commit already has a why (e.g. when amending).
 func (w *why) InitCommitInfo(commit *gc.Commit) error {
+	if commit.Extras["why"] != nil {
+		return nil
+	}
 	placeholder := ""
 	commit.Extras["why"] = &placeholder
 	return nil
@@ -75,5 +124,9 @@ func (w *why) IsActive() bool {
 // The why module is a github.com/nantli/goodcommit module that can be used to prompt the user
 // to explain why the change was needed.
 func New() gc.Module {
-	return &why{config: gc.ModuleConfig{Name: MODULE_NAME}}
+	return &why{config: gc.ModuleConfig{Name: MODULE_NAME}, MaxLength: DEFAULT_MAX_LENGTH}
+}
+
+func init() {
+	gc.Register(MODULE_NAME, New)
 }